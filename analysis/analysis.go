@@ -0,0 +1,32 @@
+// Package analysis holds passes over a complete timeline
+// Regexes handlers only know about a single node at a given time, these passes can correlate what every nodes logged
+package analysis
+
+import (
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+// walk sends every events of the timeline in chronological order, the same order TimelineCLI would print them
+// the timeline given is not modified
+func walk(timeline types.Timeline, f func(node string, li types.LogInfo)) {
+	t := make(types.Timeline, len(timeline))
+	for node, lt := range timeline {
+		t[node] = lt
+	}
+
+	for nextNodes := t.IterateNode(); len(nextNodes) != 0; nextNodes = t.IterateNode() {
+		for _, node := range nextNodes {
+			f(node, t[node][0])
+			t.Dequeue(node)
+		}
+	}
+}
+
+func dateOf(li types.LogInfo) time.Time {
+	if li.Date == nil {
+		return time.Time{}
+	}
+	return li.Date.Time
+}
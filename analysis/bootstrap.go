@@ -0,0 +1,226 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// Confidence tells how much a bootstrap advice can be trusted
+type Confidence string
+
+const (
+	ConfidenceHigh   Confidence = "high"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceLow    Confidence = "low"
+)
+
+// BootstrapEvidence is what a node logged that matters to pick the node to bootstrap from
+type BootstrapEvidence struct {
+	Position Position `json:"position"`
+
+	// SafeToBootstrap and NoGrastate are from the last time the node read its grastate.dat
+	// they are nil when the logs did not tell
+	SafeToBootstrap *bool `json:"safeToBootstrap,omitempty"`
+	NoGrastate      *bool `json:"noGrastate,omitempty"`
+
+	// LastStop is "crash" or "shutdown", empty when the node was never seen stopping
+	LastStop string `json:"lastStop,omitempty"`
+
+	// Recovered is true when wsrep recovery ran after the node last crashed, false when it crashed and none was found afterward
+	// it is nil when the node was not seen crashing nor recovering
+	Recovered *bool `json:"recovered,omitempty"`
+
+	// LastPrimary is the date of the last primary component the node was part of
+	LastPrimary        time.Time `json:"lastPrimary"`
+	LastPrimaryMembers int       `json:"lastPrimaryMembers,omitempty"`
+}
+
+// BootstrapAdvice recommends a node to bootstrap from after a full cluster outage
+type BootstrapAdvice struct {
+	Node       string     `json:"node,omitempty"` // empty when no node could be recommended
+	Confidence Confidence `json:"confidence,omitempty"`
+	Reasons    []string   `json:"reasons"`
+	Warnings   []string   `json:"warnings,omitempty"`
+
+	// Evidence is sorted as PositionsReport.Positions, from the most advanced node
+	Evidence    []BootstrapEvidence `json:"evidence"`
+	ClusterUUID string              `json:"clusterUUID,omitempty"`
+}
+
+var (
+	uncleanStopRegexes = []string{"RegexGotSignal6", "RegexGotSignal11", "RegexMariaDBGotSignal6", "RegexMariaDBGotSignal11", "RegexAssertionFailure", "RegexAborting"}
+	cleanStopRegexes   = []string{"RegexShutdownComplete", "RegexMariaDBShutdownComplete", "RegexTerminated"}
+	startRegexes       = []string{"RegexStarting", "RegexMariaDBStarting", "RegexMariaDBServerStarting"}
+)
+
+// AdviseBootstrap picks the node having the most advanced state, and tells how much the logs back it up
+func AdviseBootstrap(timeline types.Timeline) BootstrapAdvice {
+	positions := Positions(timeline)
+	advice := BootstrapAdvice{ClusterUUID: positions.ClusterUUID, Warnings: positions.Warnings}
+
+	for _, p := range positions.Positions {
+		e := bootstrapEvidence(timeline[p.Node])
+		e.Position = p
+		advice.Evidence = append(advice.Evidence, e)
+	}
+	evidences := map[string]*BootstrapEvidence{}
+	for i := range advice.Evidence {
+		evidences[advice.Evidence[i].Position.Node] = &advice.Evidence[i]
+	}
+
+	safe := []string{}
+	lastPrimary := ""
+	for _, e := range advice.Evidence {
+		node := e.Position.Node
+		if isTrue(e.SafeToBootstrap) {
+			safe = append(safe, node)
+		}
+		if isTrue(e.NoGrastate) {
+			advice.Warnings = append(advice.Warnings, node+": grastate.dat was missing when it last started, its data may not be usable")
+		}
+		if e.LastStop == "crash" && !isTrue(e.Recovered) {
+			advice.Warnings = append(advice.Warnings, node+": crashed without wsrep recovery output afterward, run mysqld --wsrep-recover to get its actual position")
+		}
+		if !e.LastPrimary.IsZero() && (lastPrimary == "" || e.LastPrimary.After(evidences[lastPrimary].LastPrimary)) {
+			lastPrimary = node
+		}
+	}
+	sort.Strings(safe)
+	if len(safe) > 1 {
+		advice.Warnings = append(advice.Warnings, "multiple nodes have safe_to_bootstrap: 1 ("+strings.Join(safe, ", ")+"), only one node should have been the last to leave")
+	}
+
+	// positions are sorted: candidates are the first usable ones having the same seqno
+	candidates := []string{}
+	for _, p := range positions.Positions {
+		if !positions.Comparable(p) || isTrue(evidences[p.Node].NoGrastate) {
+			continue
+		}
+		if len(candidates) > 0 && evidences[candidates[0]].Position.Seqno != p.Seqno {
+			break
+		}
+		candidates = append(candidates, p.Node)
+	}
+
+	switch {
+	case len(candidates) > 0:
+		adviseFromPositions(&advice, evidences, candidates, lastPrimary)
+	case len(safe) == 1:
+		advice.Node, advice.Confidence = safe[0], ConfidenceLow
+		advice.Reasons = append(advice.Reasons, "no comparable seqno found, but it has safe_to_bootstrap: 1")
+	case lastPrimary != "":
+		advice.Node, advice.Confidence = lastPrimary, ConfidenceLow
+		advice.Reasons = append(advice.Reasons, "no comparable seqno found, but it was the last node seen in a primary component")
+	default:
+		return advice
+	}
+
+	chosen := evidences[advice.Node]
+	if len(safe) > 1 {
+		lowerConfidence(&advice, ConfidenceLow)
+	}
+	if len(safe) > 0 && !isTrue(chosen.SafeToBootstrap) {
+		advice.Warnings = append(advice.Warnings, "safe_to_bootstrap: 1 was found on "+strings.Join(safe, ", ")+", not on "+advice.Node+". It will have to be set manually in its grastate.dat")
+		advice.Confidence = ConfidenceLow
+	}
+	if lastPrimary != "" && lastPrimary != advice.Node && evidences[lastPrimary].LastPrimary.After(chosen.LastPrimary) {
+		advice.Warnings = append(advice.Warnings, fmt.Sprintf("%s was part of a primary component after %s, check their positions with mysqld --wsrep-recover", lastPrimary, advice.Node))
+		lowerConfidence(&advice, ConfidenceMedium)
+	}
+	return advice
+}
+
+func adviseFromPositions(advice *BootstrapAdvice, evidences map[string]*BootstrapEvidence, candidates []string, lastPrimary string) {
+	advice.Confidence = ConfidenceHigh
+
+	// ties are broken with safe_to_bootstrap, then with the last primary component
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ei, ej := evidences[candidates[i]], evidences[candidates[j]]
+		if isTrue(ei.SafeToBootstrap) != isTrue(ej.SafeToBootstrap) {
+			return isTrue(ei.SafeToBootstrap)
+		}
+		return ei.LastPrimary.After(ej.LastPrimary)
+	})
+	advice.Node = candidates[0]
+	chosen := evidences[advice.Node]
+
+	reason := fmt.Sprintf("highest seqno: %d", chosen.Position.Seqno)
+	if advice.ClusterUUID != "" {
+		reason += " (cluster " + advice.ClusterUUID + ")"
+	}
+	advice.Reasons = append(advice.Reasons, reason)
+	if len(candidates) > 1 {
+		advice.Reasons = append(advice.Reasons, "same seqno as "+strings.Join(candidates[1:], ", "))
+		lowerConfidence(advice, ConfidenceMedium)
+	}
+	if isTrue(chosen.SafeToBootstrap) {
+		advice.Reasons = append(advice.Reasons, "safe_to_bootstrap: 1 in its grastate.dat")
+	}
+	if lastPrimary == advice.Node {
+		advice.Reasons = append(advice.Reasons, fmt.Sprintf("last node seen in a primary component (n=%d)", chosen.LastPrimaryMembers))
+	}
+	switch {
+	case isTrue(chosen.Recovered):
+		advice.Reasons = append(advice.Reasons, "its position was given by wsrep recovery")
+	case chosen.LastStop == "shutdown":
+		advice.Reasons = append(advice.Reasons, "it was shut down cleanly")
+	default:
+		// the seqno is only a lower bound: another node could be more advanced
+		lowerConfidence(advice, ConfidenceMedium)
+	}
+
+	// nodes without positions could be more advanced
+	for _, e := range evidences {
+		if !e.Position.Known() || (e.LastStop == "crash" && !isTrue(e.Recovered)) {
+			lowerConfidence(advice, ConfidenceMedium)
+		}
+	}
+}
+
+func lowerConfidence(advice *BootstrapAdvice, c Confidence) {
+	order := map[Confidence]int{ConfidenceHigh: 2, ConfidenceMedium: 1, ConfidenceLow: 0}
+	if order[c] < order[advice.Confidence] {
+		advice.Confidence = c
+	}
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// bootstrapEvidence only sets what the logs told: a node which never logged its grastate.dat has no safe_to_bootstrap
+func bootstrapEvidence(lt types.LocalTimeline) BootstrapEvidence {
+	e := BootstrapEvidence{}
+	for _, li := range lt {
+		switch {
+		case utils.SliceContains(startRegexes, li.RegexUsed):
+			e.SafeToBootstrap, e.NoGrastate = nil, nil
+		case utils.SliceContains(uncleanStopRegexes, li.RegexUsed):
+			e.LastStop, e.Recovered = "crash", boolPtr(false)
+		case utils.SliceContains(cleanStopRegexes, li.RegexUsed):
+			e.LastStop = "shutdown"
+		case li.RegexUsed == "RegexWsrepRecovery":
+			e.Recovered = boolPtr(true)
+		case li.RegexUsed == "RegexSafeToBoostrapSet":
+			e.SafeToBootstrap, e.NoGrastate = boolPtr(true), boolPtr(false)
+		case li.RegexUsed == "RegexWsrepUnsafeBootstrap":
+			// it refused to bootstrap because of safe_to_bootstrap: 0
+			e.SafeToBootstrap, e.NoGrastate = boolPtr(false), boolPtr(false)
+		case li.RegexUsed == "RegexNoGrastate":
+			e.NoGrastate = boolPtr(true)
+		case li.RegexUsed == "RegexNewComponent" && li.Ctx.State() != "NON-PRIMARY":
+			e.LastPrimary = dateOf(li)
+			e.LastPrimaryMembers = li.Ctx.MemberCount
+		}
+	}
+	return e
+}
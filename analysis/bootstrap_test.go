@@ -0,0 +1,130 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdviseBootstrap(t *testing.T) {
+	tests := []struct {
+		name               string
+		lines              map[string][]testLine
+		expectedNode       string
+		expectedConfidence Confidence
+		expectedWarnings   []string // substrings
+	}{
+		{
+			name: "recovered and safe to bootstrap",
+			lines: map[string][]testLine{
+				"node1": {
+					{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 1"},
+					{10, "RegexGotSignal11", "mysqld got signal 11 ;"},
+					{20, "RegexWsrepRecovery", "Recovered position 8c9b5610-e020-11ed-a5ea-e253cc5f629d:152"},
+					{21, "RegexSafeToBoostrapSet", "Found saved state: 8c9b5610-e020-11ed-a5ea-e253cc5f629d:-1, safe_to_bootstrap: 1"},
+				},
+				"node2": {
+					{0, "RegexShift", "Shifting SYNCED -> CLOSED (TO: 140)"},
+					{1, "RegexShutdownComplete", "mysqld: Shutdown complete"},
+				},
+			},
+			expectedNode:       "node1",
+			expectedConfidence: ConfidenceHigh,
+		},
+		{
+			name: "conflicting safe_to_bootstrap",
+			lines: map[string][]testLine{
+				"node1": {
+					{0, "RegexSafeToBoostrapSet", "Found saved state: 8c9b5610-e020-11ed-a5ea-e253cc5f629d:-1, safe_to_bootstrap: 1"},
+					{1, "RegexWsrepRecovery", "Recovered position 8c9b5610-e020-11ed-a5ea-e253cc5f629d:100"},
+				},
+				"node2": {
+					{0, "RegexSafeToBoostrapSet", "Found saved state: 8c9b5610-e020-11ed-a5ea-e253cc5f629d:-1, safe_to_bootstrap: 1"},
+					{1, "RegexWsrepRecovery", "Recovered position 8c9b5610-e020-11ed-a5ea-e253cc5f629d:120"},
+				},
+			},
+			expectedNode:       "node2",
+			expectedConfidence: ConfidenceLow,
+			expectedWarnings:   []string{"multiple nodes have safe_to_bootstrap"},
+		},
+		{
+			name: "missing recovery",
+			lines: map[string][]testLine{
+				"node1": {
+					{0, "RegexShift", "Shifting JOINED -> SYNCED (TO: 100)"},
+					{10, "RegexShutdownComplete", "mysqld: Shutdown complete"},
+				},
+				"node2": {
+					{0, "RegexShift", "Shifting JOINED -> SYNCED (TO: 90)"},
+					{20, "RegexGotSignal6", "mysqld got signal 6 ;"},
+				},
+			},
+			expectedNode:       "node1",
+			expectedConfidence: ConfidenceMedium,
+			expectedWarnings:   []string{"node2: crashed without wsrep recovery"},
+		},
+		{
+			name: "no grastate",
+			lines: map[string][]testLine{
+				"node1": {
+					{0, "RegexNoGrastate", "Could not open state file for reading: '/var/lib/mysql//grastate.dat'"},
+					{1, "RegexWsrepRecovery", "Recovered position 8c9b5610-e020-11ed-a5ea-e253cc5f629d:500"},
+				},
+				"node2": {
+					{0, "RegexWsrepRecovery", "Recovered position 8c9b5610-e020-11ed-a5ea-e253cc5f629d:120"},
+				},
+			},
+			expectedNode:       "node2",
+			expectedConfidence: ConfidenceHigh,
+			expectedWarnings:   []string{"node1: grastate.dat was missing"},
+		},
+	}
+
+	for _, test := range tests {
+		advice := AdviseBootstrap(buildTimeline(test.lines))
+		if advice.Node != test.expectedNode || advice.Confidence != test.expectedConfidence {
+			t.Errorf("%s: expected %s (%s), got %s (%s): %+v", test.name, test.expectedNode, test.expectedConfidence, advice.Node, advice.Confidence, advice)
+		}
+		if test.expectedNode != "" && len(advice.Reasons) == 0 {
+			t.Errorf("%s: no reasons given", test.name)
+		}
+		warnings := strings.Join(advice.Warnings, "\n")
+		for _, w := range test.expectedWarnings {
+			if !strings.Contains(warnings, w) {
+				t.Errorf("%s: expected a warning containing %q, got %v", test.name, w, advice.Warnings)
+			}
+		}
+	}
+}
+
+// nothing about grastate.dat nor recoveries was logged: it should not be read as safe_to_bootstrap: 0 or as a found grastate.dat
+func TestBootstrapEvidenceUnknown(t *testing.T) {
+	advice := AdviseBootstrap(buildTimeline(map[string][]testLine{
+		"node1": {
+			{0, "RegexShift", "Shifting JOINED -> SYNCED (TO: 100)"},
+			{10, "RegexShutdownComplete", "mysqld: Shutdown complete"},
+		},
+	}))
+
+	if len(advice.Evidence) != 1 {
+		t.Fatalf("expected evidence for a single node, got %+v", advice.Evidence)
+	}
+	e := advice.Evidence[0]
+	if e.SafeToBootstrap != nil || e.NoGrastate != nil || e.Recovered != nil {
+		t.Errorf("expected unknown evidence, got safe_to_bootstrap=%v no grastate=%v recovered=%v", e.SafeToBootstrap, e.NoGrastate, e.Recovered)
+	}
+	if advice.Node != "node1" {
+		t.Errorf("expected node1, got %+v", advice)
+	}
+
+	advice = AdviseBootstrap(buildTimeline(map[string][]testLine{
+		"node1": {
+			{0, "RegexNoGrastate", "Could not open state file for reading: '/var/lib/mysql//grastate.dat'"},
+			{1, "RegexWsrepUnsafeBootstrap", "[ERROR] WSREP: It may not be safe to bootstrap the cluster from this node."},
+			{2, "RegexWsrepRecovery", "Recovered position 8c9b5610-e020-11ed-a5ea-e253cc5f629d:120"},
+		},
+	}))
+	e = advice.Evidence[0]
+	if e.SafeToBootstrap == nil || *e.SafeToBootstrap || e.NoGrastate == nil || *e.NoGrastate || e.Recovered == nil || !*e.Recovered {
+		t.Errorf("expected known evidence, got safe_to_bootstrap=%v no grastate=%v recovered=%v", e.SafeToBootstrap, e.NoGrastate, e.Recovered)
+	}
+}
@@ -0,0 +1,161 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Finding is a known issue found in the timeline
+type Finding struct {
+	Rule     string    `json:"rule"`
+	Severity Severity  `json:"severity"`
+	Date     time.Time `json:"date"`
+	Node     string    `json:"node"` // empty for findings about the whole cluster
+	Title    string    `json:"title"`
+	Details  string    `json:"details,omitempty"`
+	Advice   string    `json:"advice"`
+	Log      string    `json:"log,omitempty"` // the raw log that triggered it
+}
+
+// Rule is a known issue to look for
+// Check walks the timeline and returns what was found. Rule, Severity, Title and Advice are filled afterward
+type Rule struct {
+	Name     string
+	Severity Severity
+	Title    string
+	Advice   string
+	Check    func(timeline types.Timeline) []Finding
+}
+
+var Rules = []Rule{
+	{
+		Name:     "donor-only-synced",
+		Severity: SeverityWarning,
+		Title:    "SST donor was the only SYNCED node",
+		Advice:   "With only 2 members, the donor is the only node able to serve traffic while the transfer runs, and it can be blocked or slowed down by it. Run at least 3 nodes, or make sure the gcache is big enough for the joiner to use IST.",
+		Check:    checkDonorOnlySynced,
+	},
+	{
+		Name:     "unsafe-bootstrap",
+		Severity: SeverityCritical,
+		Title:    "node bootstrapped with safe_to_bootstrap=0",
+		Advice:   "This node may not have the most recent data. Compare seqnos of every node (grastate.dat, or mysqld --wsrep-recover) and bootstrap the most advanced one before setting safe_to_bootstrap: 1 in its grastate.dat.",
+		Check:    checkRegex("RegexWsrepUnsafeBootstrap"),
+	},
+	{
+		Name:     "ist-fallback-sst",
+		Severity: SeverityWarning,
+		Title:    "joiner failed to prepare IST then fell back to SST",
+		Advice:   "The donor gcache did not hold every missing writesets. Increase gcache.size so that restarts can use IST instead of a full SST.",
+		Check:    checkISTFallbackSST,
+	},
+	{
+		Name:     "port-in-use",
+		Severity: SeverityCritical,
+		Title:    "port already in use on restart",
+		Advice:   "Another process, usually a previous mysqld that did not stop yet, is still listening on the galera port. Check it with 'ss -ltnp' before starting the node again.",
+		Check:    checkRegex("RegexBindAddressAlreadyUsed"),
+	},
+	{
+		Name:     "split-brain",
+		Severity: SeverityCritical,
+		Title:    "more than one primary component at the same time",
+		Advice:   "Both sides could accept writes and their data have diverged. This is only possible when quorum was bypassed (pc.ignore_sb, pc.ignore_quorum, or a node bootstrapped while the others were running). Pick the side to keep, and rebuild the other nodes from it with SST.",
+		Check:    checkPartitions(true),
+	},
+	{
+		Name:     "network-partition",
+		Severity: SeverityWarning,
+		Title:    "nodes disagreed on the cluster membership",
+		Advice:   "Nodes were running in different views: they could not reach each other. Check the network between them (firewalls, latency, packet loss) around that time, and whether evs.suspect_timeout and evs.inactive_timeout suit it.",
+		Check:    checkPartitions(false),
+	},
+	{
+		Name:     "asymmetric-suspect",
+		Severity: SeverityWarning,
+		Title:    "node suspected by a peer that did not suspect it back",
+		Advice:   "The network may only be failing in one direction, or the suspected node was too busy to answer. Check firewalls and routes both ways between the two nodes, and the load of the suspected one.",
+		Check:    checkAsymmetricSuspects,
+	},
+}
+
+// Diagnose evaluates every rules on the timeline and returns findings in chronological order
+func Diagnose(timeline types.Timeline, rules []Rule) []Finding {
+	findings := []Finding{}
+	for _, rule := range rules {
+		for _, f := range rule.Check(timeline) {
+			f.Rule = rule.Name
+			f.Severity = rule.Severity
+			f.Title = rule.Title
+			f.Advice = rule.Advice
+			findings = append(findings, f)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Date.Before(findings[j].Date)
+	})
+	return findings
+}
+
+func newFinding(node string, li types.LogInfo, details string) Finding {
+	return Finding{Date: dateOf(li), Node: node, Details: details, Log: li.Log}
+}
+
+// checkRegex is for issues that can be identified with a single log line
+func checkRegex(regexKey string) func(types.Timeline) []Finding {
+	return func(timeline types.Timeline) []Finding {
+		findings := []Finding{}
+		walk(timeline, func(node string, li types.LogInfo) {
+			if li.RegexUsed == regexKey {
+				findings = append(findings, newFinding(node, li, ""))
+			}
+		})
+		return findings
+	}
+}
+
+func checkDonorOnlySynced(timeline types.Timeline) []Finding {
+	findings := []Finding{}
+	wasDonor := map[string]bool{}
+	walk(timeline, func(node string, li types.LogInfo) {
+		isDonor := li.Ctx.State() == "DONOR"
+		if isDonor && !wasDonor[node] && li.Ctx.MemberCount == 2 {
+			details := "2 members in the cluster"
+			if joiner := li.Ctx.SST.ResyncingNode; joiner != "" {
+				details = fmt.Sprintf("2 members in the cluster, the other one (%s) was joining", joiner)
+			}
+			findings = append(findings, newFinding(node, li, details))
+		}
+		wasDonor[node] = isDonor
+	})
+	return findings
+}
+
+func checkISTFallbackSST(timeline types.Timeline) []Finding {
+	findings := []Finding{}
+	failedIST := map[string]int{}
+	walk(timeline, func(node string, li types.LogInfo) {
+		switch li.RegexUsed {
+		case "RegexFailedToPrepareIST":
+			failedIST[node] += 1 + li.RepetitionCount
+		case "RegexSSTRequestSuccess":
+			if failedIST[node] == 0 {
+				return
+			}
+			findings = append(findings, newFinding(node, li, fmt.Sprintf("IST could not be prepared %d time(s) before requesting a state transfer", failedIST[node])))
+			failedIST[node] = 0
+		}
+	})
+	return findings
+}
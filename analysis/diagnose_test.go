@@ -0,0 +1,48 @@
+package analysis
+
+import (
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	lines := map[string][]testLine{
+		"node1": {
+			{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 2"},
+			{10, "RegexShift", "Shifting SYNCED -> DONOR/DESYNCED (TO: 21582507)"},
+			{20, "RegexShift", "Shifting DONOR/DESYNCED -> JOINED (TO: 21582507)"},
+			{21, "RegexShift", "Shifting JOINED -> SYNCED (TO: 21582507)"},
+		},
+		"node2": {
+			{1, "RegexFailedToPrepareIST", "Failed to prepare for incremental state transfer: Local state seqno is undefined: 1 (Operation not permitted)"},
+			{2, "RegexSSTRequestSuccess", "Member 1.0 (node2) requested state transfer from '*any*'. Selected 0.0 (node1)(SYNCED) as donor."},
+			{30, "RegexBindAddressAlreadyUsed", "failed to open gcomm backend connection: 98: error while trying to listen 'tcp://0.0.0.0:4567?socket.non_blocking=1', asio error 'bind: Address already in use': 98 (Address already in use)"},
+		},
+		"node3": {
+			{40, "RegexWsrepUnsafeBootstrap", "[ERROR] [MY-000000] [Galera] It may not be safe to bootstrap the cluster from this node. It was not the last one to leave the cluster and may not contain all the updates."},
+			// 3 members: should not be reported
+			{41, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 3"},
+			{42, "RegexShift", "Shifting SYNCED -> DONOR/DESYNCED (TO: 21582507)"},
+		},
+	}
+
+	expected := []struct {
+		node, rule string
+		severity   Severity
+	}{
+		{"node2", "ist-fallback-sst", SeverityWarning},
+		{"node1", "donor-only-synced", SeverityWarning},
+		{"node2", "port-in-use", SeverityCritical},
+		{"node3", "unsafe-bootstrap", SeverityCritical},
+	}
+
+	findings := Diagnose(buildTimeline(lines), Rules)
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %d: %v", len(expected), len(findings), findings)
+	}
+	for i, e := range expected {
+		f := findings[i]
+		if f.Node != e.node || f.Rule != e.rule || f.Severity != e.severity || f.Advice == "" || f.Title == "" {
+			t.Errorf("finding %d: expected %v, got %v", i, e, f)
+		}
+	}
+}
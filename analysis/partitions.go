@@ -0,0 +1,258 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// Nodes install the same view a few seconds apart: shorter disagreements are not partitions
+const minPartitionDuration = viewMergeWindow
+
+// Nodes losing each other suspect each other within this window
+const suspectWindow = time.Minute
+
+// PartitionComponent is a group of nodes sharing the same view during a partition
+type PartitionComponent struct {
+	Nodes       []string `json:"nodes"`
+	Primary     bool     `json:"primary"`
+	MemberCount int      `json:"memberCount"`
+	Members     []string `json:"members,omitempty"`
+}
+
+// Partition is a period when running nodes disagreed on the cluster membership
+type Partition struct {
+	Start      time.Time            `json:"start"`
+	End        time.Time            `json:"end"`
+	Components []PartitionComponent `json:"components"`
+
+	// SplitBrain is when more than one component was primary at once
+	SplitBrain bool `json:"splitBrain"`
+}
+
+func (p Partition) String() string {
+	components := []string{}
+	for _, c := range p.Components {
+		components = append(components, strings.Join(c.Nodes, ", ")+": "+c.Status())
+	}
+	return strings.Join(components, " / ") + ", for " + p.End.Sub(p.Start).Round(time.Second).String()
+}
+
+func (c PartitionComponent) Status() string {
+	status := "PRIMARY"
+	if !c.Primary {
+		status = "NON-PRIMARY"
+	}
+	return status + "(n=" + strconv.Itoa(c.MemberCount) + ")"
+}
+
+// viewSegment is a period a node spent in a view
+type viewSegment struct {
+	node       string
+	start, end time.Time
+	view       *ClusterView
+}
+
+// Partitions finds periods when running nodes were in different views at the same time
+// views installed by a node last until its next view, until it stops, or until the end of its logs
+func Partitions(timeline types.Timeline) []Partition {
+	_, installed := buildViews(timeline)
+	segments := viewSegments(timeline, installed)
+
+	boundaries := []time.Time{}
+	for _, s := range segments {
+		boundaries = append(boundaries, s.start, s.end)
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+
+	partitions := []Partition{}
+	var (
+		current  *Partition
+		longest  time.Duration // components of a partition are the ones that lasted the longest
+		finished = func() {
+			if current != nil && current.End.Sub(current.Start) >= minPartitionDuration {
+				partitions = append(partitions, *current)
+			}
+			current = nil
+		}
+	)
+	for i := 0; i+1 < len(boundaries); i++ {
+		from, to := boundaries[i], boundaries[i+1]
+		if !from.Before(to) {
+			continue
+		}
+
+		views := []*ClusterView{}
+		nodes := map[*ClusterView][]string{}
+		for _, s := range segments {
+			if s.start.After(from) || !s.end.After(from) {
+				continue
+			}
+			if _, ok := nodes[s.view]; !ok {
+				views = append(views, s.view)
+			}
+			nodes[s.view] = append(nodes[s.view], s.node)
+		}
+
+		if len(views) < 2 {
+			finished()
+			continue
+		}
+		if current == nil {
+			current = &Partition{Start: from}
+			longest = 0
+		}
+		current.End = to
+		if to.Sub(from) > longest {
+			longest = to.Sub(from)
+			current.Components = partitionComponents(views, nodes)
+		}
+	}
+	finished()
+
+	for i := range partitions {
+		primaries := 0
+		for _, c := range partitions[i].Components {
+			if c.Primary {
+				primaries++
+			}
+		}
+		partitions[i].SplitBrain = primaries > 1
+	}
+	return partitions
+}
+
+func partitionComponents(views []*ClusterView, nodes map[*ClusterView][]string) []PartitionComponent {
+	components := []PartitionComponent{}
+	for _, v := range views {
+		n := append([]string{}, nodes[v]...)
+		sort.Strings(n)
+		components = append(components, PartitionComponent{Nodes: n, Primary: v.Primary, MemberCount: v.MemberCount, Members: v.Members})
+	}
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].Primary != components[j].Primary {
+			return components[i].Primary
+		}
+		return components[i].Nodes[0] < components[j].Nodes[0]
+	})
+	return components
+}
+
+func viewSegments(timeline types.Timeline, installed map[string][]nodeView) []viewSegment {
+	segments := []viewSegment{}
+	for node, lt := range timeline {
+		var (
+			current  *viewSegment
+			k        int
+			lastDate time.Time
+		)
+		closeAt := func(date time.Time) {
+			if current != nil && date.After(current.start) {
+				current.end = date
+				segments = append(segments, *current)
+			}
+			current = nil
+		}
+
+		for _, li := range lt {
+			date := dateOf(li)
+			if date.IsZero() {
+				continue
+			}
+			lastDate = date
+
+			switch {
+			case li.RegexUsed == "RegexNewComponent" && k < len(installed[node]):
+				closeAt(date)
+				current = &viewSegment{node: node, start: date, view: installed[node][k].view}
+				k++
+			case utils.SliceContains(startRegexes, li.RegexUsed), utils.SliceContains(uncleanStopRegexes, li.RegexUsed), utils.SliceContains(cleanStopRegexes, li.RegexUsed):
+				closeAt(date)
+			case li.Ctx.State() == "CLOSED" || li.Ctx.State() == "DESTROYED":
+				closeAt(date)
+			}
+		}
+		closeAt(lastDate)
+	}
+	return segments
+}
+
+func checkPartitions(splitBrain bool) func(types.Timeline) []Finding {
+	return func(timeline types.Timeline) []Finding {
+		findings := []Finding{}
+		for _, p := range Partitions(timeline) {
+			if p.SplitBrain == splitBrain {
+				findings = append(findings, Finding{Date: p.Start, Details: p.String()})
+			}
+		}
+		return findings
+	}
+}
+
+type suspicion struct {
+	date      time.Time
+	node      string
+	suspected string
+}
+
+// checkAsymmetricSuspects finds nodes suspected by a peer, while they did not suspect that peer back
+// only nodes whose logs cover the date can be checked
+func checkAsymmetricSuspects(timeline types.Timeline) []Finding {
+	latest := timeline.GetLatestUpdatedContextsByNodes()
+	nodeOf := func(hash string, ctx types.LogCtx) string {
+		name := memberName(hash, ctx)
+		for node, nodeCtx := range latest {
+			if utils.SliceContains(nodeCtx.OwnHashes, hash) || utils.SliceContains(nodeCtx.OwnNames, name) || utils.SliceContains(nodeCtx.OwnIPs, name) {
+				return node
+			}
+		}
+		return ""
+	}
+
+	suspicions := []suspicion{}
+	lines := []types.LogInfo{}
+	walk(timeline, func(node string, li types.LogInfo) {
+		if li.RegexUsed != "RegexNodeSuspect" {
+			return
+		}
+		submatches := regex.ViewsMap[li.RegexUsed].Submatches(li.Log)
+		if submatches == nil {
+			return
+		}
+		suspected := nodeOf(submatches[regex.GroupNodeHash], li.Ctx)
+		if suspected == "" || suspected == node {
+			return
+		}
+		suspicions = append(suspicions, suspicion{date: dateOf(li), node: node, suspected: suspected})
+		lines = append(lines, li)
+	})
+
+	findings := []Finding{}
+	reported := map[string]time.Time{}
+	for i, s := range suspicions {
+		lt := timeline[s.suspected]
+		if len(lt) == 0 || s.date.Before(dateOf(lt[0])) || s.date.After(dateOf(lt[len(lt)-1])) {
+			continue
+		}
+		mutual := false
+		for _, other := range suspicions {
+			if other.node == s.suspected && other.suspected == s.node && abs(other.date.Sub(s.date)) <= suspectWindow {
+				mutual = true
+				break
+			}
+		}
+		key := s.node + "/" + s.suspected
+		if last, ok := reported[key]; mutual || (ok && s.date.Sub(last) <= suspectWindow) {
+			continue
+		}
+		reported[key] = s.date
+		findings = append(findings, newFinding(s.node, lines[i], fmt.Sprintf("%s suspected %s to be down, %s did not suspect %s back", s.node, s.suspected, s.suspected, s.node)))
+	}
+	return findings
+}
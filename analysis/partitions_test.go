@@ -0,0 +1,111 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPartitions(t *testing.T) {
+	tests := []struct {
+		name               string
+		lines              map[string][]testLine
+		expectedPartitions int
+		expectedSplitBrain bool
+		expectedDuration   time.Duration
+	}{
+		{
+			name: "isolated node",
+			lines: map[string][]testLine{
+				"node1": {
+					{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 3"},
+					{50, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 2"},
+					{400, "RegexPreparingBackup", "Preparing the backup at /var/lib/mysql/sst-xb-tmpdir"},
+				},
+				"node2": {
+					{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 1, memb_num = 3"},
+					{51, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 1, memb_num = 2"},
+					{400, "RegexPreparingBackup", "Preparing the backup at /var/lib/mysql/sst-xb-tmpdir"},
+				},
+				"node3": {
+					{1, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 2, memb_num = 3"},
+					{49, "RegexNewComponent", "New COMPONENT: primary = no, bootstrap = no, my_idx = 0, memb_num = 1"},
+					{350, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 2, memb_num = 3"},
+				},
+			},
+			expectedPartitions: 1,
+			expectedDuration:   301 * time.Second, // from node3 leaving, until it came back
+		},
+		{
+			name: "node shutting down",
+			lines: map[string][]testLine{
+				"node1": {
+					{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 2"},
+					{50, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 1"},
+					{400, "RegexPreparingBackup", "Preparing the backup at /var/lib/mysql/sst-xb-tmpdir"},
+				},
+				"node2": {
+					{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 1, memb_num = 2"},
+					{49, "RegexNewComponent", "New COMPONENT: primary = no, bootstrap = no, my_idx = 0, memb_num = 1"},
+					{50, "RegexShutdownComplete", "mysqld: Shutdown complete"},
+				},
+			},
+		},
+		{
+			name: "split brain",
+			lines: map[string][]testLine{
+				"node1": {
+					{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 2"},
+					{100, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = yes, my_idx = 0, memb_num = 1"},
+					{400, "RegexPreparingBackup", "Preparing the backup at /var/lib/mysql/sst-xb-tmpdir"},
+				},
+				"node2": {
+					{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 1, memb_num = 2"},
+					{100, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = yes, my_idx = 0, memb_num = 1"},
+					{400, "RegexPreparingBackup", "Preparing the backup at /var/lib/mysql/sst-xb-tmpdir"},
+				},
+			},
+			expectedPartitions: 1,
+			expectedSplitBrain: true,
+			expectedDuration:   300 * time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		partitions := Partitions(buildTimeline(test.lines))
+		if len(partitions) != test.expectedPartitions {
+			t.Errorf("%s: expected %d partitions, got %+v", test.name, test.expectedPartitions, partitions)
+			continue
+		}
+		if len(partitions) == 0 {
+			continue
+		}
+		p := partitions[0]
+		if p.SplitBrain != test.expectedSplitBrain || p.End.Sub(p.Start) != test.expectedDuration || len(p.Components) != 2 {
+			t.Errorf("%s: unexpected partition: %+v", test.name, p)
+		}
+	}
+}
+
+func TestCheckAsymmetricSuspects(t *testing.T) {
+	lines := map[string][]testLine{
+		"node1": {
+			{0, "RegexMemberAssociations", "	1: 08dd5580-32f7-11ed-a9eb-af5e3d01519e, node2"},
+			{10, "RegexNodeSuspect", "evs::proto(015702fc-a4ca, OPERATIONAL, view_id(REG,015702fc-a4ca,2)) suspecting node: 08dd5580-a9eb"},
+		},
+		"node2": {
+			{0, "RegexPreparingBackup", "Preparing the backup at /var/lib/mysql/sst-xb-tmpdir"},
+			{20, "RegexPreparingBackup", "Preparing the backup at /var/lib/mysql/sst-xb-tmpdir"},
+		},
+	}
+
+	findings := checkAsymmetricSuspects(buildTimeline(lines))
+	if len(findings) != 1 || findings[0].Node != "node1" {
+		t.Fatalf("expected node1 to be reported, got %+v", findings)
+	}
+
+	lines["node2"] = append(lines["node2"], testLine{30, "RegexMemberAssociations", "	0: 015702fc-32f5-11ed-a4ca-267f97316394, node1"})
+	lines["node2"] = append(lines["node2"], testLine{40, "RegexNodeSuspect", "evs::proto(08dd5580-a9eb, OPERATIONAL, view_id(REG,015702fc-a4ca,2)) suspecting node: 015702fc-a4ca"})
+	if findings := checkAsymmetricSuspects(buildTimeline(lines)); len(findings) != 0 {
+		t.Errorf("mutual suspicions should not be reported, got %+v", findings)
+	}
+}
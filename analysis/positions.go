@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+// Position is the latest replication position a node logged
+type Position struct {
+	Node        string    `json:"node"`
+	ClusterUUID string    `json:"clusterUUID,omitempty"`
+	Seqno       int64     `json:"seqno"` // -1 when unknown
+	Date        time.Time `json:"date"`  // when the position was logged
+	State       string    `json:"state"` // latest state of the node
+	LastSeen    time.Time `json:"lastSeen"`
+}
+
+func (p Position) Known() bool {
+	return p.Seqno >= 0
+}
+
+// PositionsReport tells which node had the most advanced state at the end of the logs
+type PositionsReport struct {
+	// Positions are sorted from the most advanced, nodes from other cluster UUIDs and unknown positions come last
+	Positions []Position `json:"positions"`
+
+	// ClusterUUID is the cluster the latest position was logged for, seqnos from other clusters can't be compared
+	ClusterUUID string `json:"clusterUUID,omitempty"`
+
+	// MostAdvanced are the nodes having the highest seqno, the ones to bootstrap from
+	MostAdvanced []string `json:"mostAdvanced"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// Positions compares the latest positions of every node
+// positions are only logged on some events: the seqno of a node that kept running is a lower bound
+func Positions(timeline types.Timeline) PositionsReport {
+	report := PositionsReport{}
+	latest := time.Time{}
+	running := map[string]bool{}
+
+	for node, lt := range timeline {
+		if len(lt) == 0 {
+			continue
+		}
+		p := Position{Node: node, Seqno: -1}
+		previous := types.LogCtx{}
+		for _, li := range lt {
+			if li.Ctx.Seqno != previous.Seqno || li.Ctx.ClusterUUID != previous.ClusterUUID {
+				p.Date = dateOf(li)
+			}
+			previous = li.Ctx
+		}
+		last := lt[len(lt)-1]
+		running[node] = last.Ctx.IsPrimary()
+		p.State = last.Ctx.State()
+		p.LastSeen = dateOf(last)
+		p.ClusterUUID = last.Ctx.ClusterUUID
+		if seqno, err := strconv.ParseInt(last.Ctx.Seqno, 10, 64); err == nil {
+			p.Seqno = seqno
+		} else {
+			p.Date = time.Time{}
+		}
+
+		if p.ClusterUUID != "" && p.Date.After(latest) {
+			latest = p.Date
+			report.ClusterUUID = p.ClusterUUID
+		}
+		report.Positions = append(report.Positions, p)
+	}
+
+	sort.Slice(report.Positions, func(i, j int) bool {
+		pi, pj := report.Positions[i], report.Positions[j]
+		if report.Comparable(pi) != report.Comparable(pj) {
+			return report.Comparable(pi)
+		}
+		if pi.Known() != pj.Known() {
+			return pi.Known()
+		}
+		if pi.Seqno != pj.Seqno {
+			return pi.Seqno > pj.Seqno
+		}
+		return pi.Node < pj.Node
+	})
+
+	for _, p := range report.Positions {
+		switch {
+		case !p.Known():
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: no position found, check its grastate.dat or run mysqld --wsrep-recover", p.Node))
+		case !report.Comparable(p):
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: last known position is from another cluster (%s), it can't be compared", p.Node, p.ClusterUUID))
+		case len(report.MostAdvanced) == 0 || report.Positions[0].Seqno == p.Seqno:
+			report.MostAdvanced = append(report.MostAdvanced, p.Node)
+		}
+		if p.Known() && running[p.Node] {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: still %s at the end of its logs, it may have applied more than seqno %d", p.Node, p.State, p.Seqno))
+		}
+	}
+	return report
+}
+
+// Comparable is true when the position can be compared with the latest cluster UUID
+// positions logged without cluster UUIDs, such as state shifts, are assumed to be from the latest cluster
+func (r PositionsReport) Comparable(p Position) bool {
+	return p.Known() && (p.ClusterUUID == "" || p.ClusterUUID == r.ClusterUUID)
+}
@@ -0,0 +1,61 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPositions(t *testing.T) {
+	lines := map[string][]testLine{
+		"node1": {
+			{0, "RegexShift", "Shifting JOINED -> SYNCED (TO: 100)"},
+			{10, "RegexInconsistencyVoteInit", "Member 1(node1) initiates vote on 8c9b5610-e020-11ed-a5ea-e253cc5f629d:150,bdb2b9234ae75cb3:  some error, Error_code: 123;"},
+			{20, "RegexGotSignal11", "mysqld got signal 11 ;"},
+			{30, "RegexWsrepRecovery", "Recovered position 8c9b5610-e020-11ed-a5ea-e253cc5f629d:152"},
+		},
+		"node2": {
+			{0, "RegexShift", "Shifting JOINED -> SYNCED (TO: 100)"},
+			{40, "RegexShift", "Shifting SYNCED -> CLOSED (TO: 152)"},
+		},
+		"node3": {
+			{0, "RegexShift", "Shifting JOINED -> SYNCED (TO: 90)"},
+			{5, "RegexInconsistencyVoteInit", "Member 1(node1) initiates vote on 8c9b5610-e020-11ed-a5ea-e253cc5f629d:120,bdb2b9234ae75cb3:  some error, Error_code: 123;"},
+		},
+		"node4": {
+			{0, "RegexWsrepRecovery", "Recovered position 11111111-e020-11ed-a5ea-e253cc5f629d:5000"},
+		},
+		"node5": {
+			{0, "RegexGotSignal11", "mysqld got signal 11 ;"},
+		},
+	}
+
+	report := Positions(buildTimeline(lines))
+
+	if report.ClusterUUID != "8c9b5610-e020-11ed-a5ea-e253cc5f629d" {
+		t.Errorf("unexpected cluster uuid: %s", report.ClusterUUID)
+	}
+	if !reflect.DeepEqual(report.MostAdvanced, []string{"node1", "node2"}) {
+		t.Errorf("unexpected most advanced nodes: %v", report.MostAdvanced)
+	}
+
+	order := []string{}
+	for _, p := range report.Positions {
+		order = append(order, p.Node)
+	}
+	if !reflect.DeepEqual(order, []string{"node1", "node2", "node3", "node4", "node5"}) {
+		t.Errorf("unexpected order: %v", order)
+	}
+
+	node1 := report.Positions[0]
+	if node1.Seqno != 152 || node1.Date.Second() != 30 || node1.State != "RECOVERY" {
+		t.Errorf("unexpected position for node1: %+v", node1)
+	}
+	if report.Positions[4].Known() {
+		t.Errorf("node5 should not have a position: %+v", report.Positions[4])
+	}
+
+	// node3 is still running, node4 is from another cluster, node5 is unknown
+	if len(report.Warnings) != 3 {
+		t.Errorf("expected 3 warnings, got %v", report.Warnings)
+	}
+}
@@ -0,0 +1,179 @@
+package analysis
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+// ClockSkew is how much a node's clock is ahead of the reference node
+// Negative offsets mean the node clock is late
+type ClockSkew struct {
+	Node      string        `json:"node"`
+	Reference string        `json:"reference"`
+	Offset    time.Duration `json:"offset"`
+	Samples   int           `json:"samples"` // how many correlated events were used
+}
+
+// anchor is an event every node logs at the same time, identified by key
+type anchor struct {
+	key  string
+	date time.Time
+}
+
+// anchorsOf returns events that are logged by every member of the cluster at once:
+// view changes with the same member count, and nodes joining or leaving with their uuid
+func anchorsOf(lt types.LocalTimeline) []anchor {
+	anchors := []anchor{}
+	for _, li := range lt {
+		if li.Date == nil {
+			continue
+		}
+		var key string
+		switch li.RegexUsed {
+		case "RegexNewComponent":
+			submatches := regex.ViewsMap["RegexNewComponent"].Submatches(li.Log)
+			if submatches == nil {
+				continue
+			}
+			key = "component:" + submatches["primary"] + ":" + submatches["memb_num"]
+		case "RegexNodeJoined", "RegexNodeLeft":
+			submatches := regex.ViewsMap[li.RegexUsed].Submatches(li.Log)
+			if submatches == nil {
+				continue
+			}
+			key = li.RegexUsed + ":" + submatches[regex.GroupNodeHash]
+		default:
+			continue
+		}
+		anchors = append(anchors, anchor{key: key, date: li.Date.Time})
+	}
+	return anchors
+}
+
+// offsetsBetween pairs each anchor of a node with the closest one of the other node having the same key
+// anchors further than window are ignored, they are most probably not the same event
+func offsetsBetween(anchors, others []anchor, window time.Duration) []time.Duration {
+	offsets := []time.Duration{}
+	for _, a := range anchors {
+		found := false
+		var closest time.Duration
+		for _, o := range others {
+			if a.key != o.key {
+				continue
+			}
+			offset := a.date.Sub(o.date)
+			if offset > window || offset < -window {
+				continue
+			}
+			if !found || abs(offset) < abs(closest) {
+				closest = offset
+				found = true
+			}
+		}
+		if found {
+			offsets = append(offsets, closest)
+		}
+	}
+	return offsets
+}
+
+// EstimateClockSkew compares when each node logged the same cluster-wide events
+// The node with the most of these events is the reference, others are compared to it, or to any node already estimated
+// Nodes that never logged the same events as the others are missing from the result
+func EstimateClockSkew(timeline types.Timeline, window time.Duration) map[string]ClockSkew {
+	anchors := map[string][]anchor{}
+	nodes := []string{}
+	for node, lt := range timeline {
+		anchors[node] = anchorsOf(lt)
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	skews := map[string]ClockSkew{}
+	if len(nodes) == 0 {
+		return skews
+	}
+
+	reference := nodes[0]
+	for _, node := range nodes {
+		if len(anchors[node]) > len(anchors[reference]) {
+			reference = node
+		}
+	}
+	skews[reference] = ClockSkew{Node: reference, Reference: reference}
+
+	for found := true; found; {
+		found = false
+		for _, node := range nodes {
+			if _, ok := skews[node]; ok {
+				continue
+			}
+
+			// compare with the estimated node sharing the most events, to have the most reliable median
+			var best []time.Duration
+			var bestBase ClockSkew
+			for _, estimated := range nodes {
+				base, ok := skews[estimated]
+				if !ok {
+					continue
+				}
+				offsets := offsetsBetween(anchors[node], anchors[estimated], window)
+				if len(offsets) > len(best) {
+					best = offsets
+					bestBase = base
+				}
+			}
+			if len(best) == 0 {
+				continue
+			}
+			skews[node] = ClockSkew{Node: node, Reference: reference, Offset: bestBase.Offset + median(best), Samples: len(best)}
+			found = true
+		}
+	}
+	return skews
+}
+
+// ApplyClockSkew stores the estimated skews in every context so that they can be displayed
+// when correct is set, dates are also shifted so that events are ordered as they happened rather than by each node's clock
+func ApplyClockSkew(timeline types.Timeline, skews map[string]ClockSkew, correct bool) types.Timeline {
+	t := make(types.Timeline, len(timeline))
+	for node, lt := range timeline {
+		skew, ok := skews[node]
+		if !ok {
+			t[node] = lt
+			continue
+		}
+		offset := skew.Offset
+		newlt := make(types.LocalTimeline, len(lt))
+		for i, li := range lt {
+			li.Ctx.ClockSkew = &offset
+			if correct && li.Date != nil {
+				li.Date = li.Date.Add(-offset)
+			}
+			newlt[i] = li
+		}
+		t[node] = newlt
+	}
+	return t
+}
+
+func median(durations []time.Duration) time.Duration {
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
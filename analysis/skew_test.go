@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateClockSkew(t *testing.T) {
+	timeline := buildTimeline(map[string][]testLine{
+		"node1": {
+			{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 2"},
+			{50, "RegexNodeJoined", "declaring 5873acd0-baa8 at tcp://172.17.0.3:4567 stable"},
+			{60, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 3"},
+			{200, "RegexNodeLeft", "forgetting 5873acd0-baa8 (tcp://172.17.0.3:4567)"},
+		},
+		// 3s ahead
+		"node2": {
+			{3, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 1, memb_num = 2"},
+			{53, "RegexNodeJoined", "declaring 5873acd0-baa8 at tcp://172.17.0.3:4567 stable"},
+			{63, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 1, memb_num = 3"},
+			{204, "RegexNodeLeft", "forgetting 5873acd0-baa8 (tcp://172.17.0.3:4567)"},
+		},
+		// 2s late compared to node2 only, it joined later
+		"node3": {
+			{61, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 2, memb_num = 3"},
+		},
+		// nothing in common
+		"node4": {
+			{10, "RegexNewComponent", "New COMPONENT: primary = no, bootstrap = no, my_idx = 0, memb_num = 1"},
+		},
+	})
+
+	skews := EstimateClockSkew(timeline, time.Minute)
+
+	expected := map[string]ClockSkew{
+		"node1": {Node: "node1", Reference: "node1"},
+		"node2": {Node: "node2", Reference: "node1", Offset: 3 * time.Second, Samples: 4},
+		"node3": {Node: "node3", Reference: "node1", Offset: time.Second, Samples: 1},
+	}
+	if len(skews) != len(expected) {
+		t.Fatalf("expected %d skews, got %v", len(expected), skews)
+	}
+	for node, skew := range expected {
+		if skews[node] != skew {
+			t.Errorf("%s: expected %+v, got %+v", node, skew, skews[node])
+		}
+	}
+
+	corrected := ApplyClockSkew(timeline, skews, true)
+	if got := corrected["node2"][0].Date.Time; !got.Equal(timeline["node1"][0].Date.Time) {
+		t.Errorf("expected node2 to be corrected to %s, got %s", timeline["node1"][0].Date.Time, got)
+	}
+	if skew := corrected["node2"][0].Ctx.ClockSkew; skew == nil || *skew != 3*time.Second {
+		t.Errorf("expected clock skew in context, got %v", skew)
+	}
+	if corrected["node4"][0].Ctx.ClockSkew != nil {
+		t.Errorf("node4 should not have a clock skew")
+	}
+	if timeline["node2"][0].Ctx.ClockSkew != nil {
+		t.Errorf("timeline was modified")
+	}
+}
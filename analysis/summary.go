@@ -0,0 +1,225 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// SummaryKind is used to sort out the importance of each summary event
+type SummaryKind string
+
+const (
+	SummaryCrash           SummaryKind = "crash"
+	SummaryPrimaryLost     SummaryKind = "primary-lost"
+	SummaryPrimaryRegained SummaryKind = "primary-regained"
+	SummaryStateTransfer   SummaryKind = "state-transfer"
+	SummaryTransferFailed  SummaryKind = "state-transfer-failed"
+	SummaryConflict        SummaryKind = "conflict"
+)
+
+// SummaryEvent is a single sentence of the cluster narrative
+type SummaryEvent struct {
+	Date time.Time   `json:"date"`
+	Node string      `json:"node,omitempty"`
+	Kind SummaryKind `json:"kind"`
+	Text string      `json:"text"`
+}
+
+var crashRegexes = map[string]string{
+	"RegexGotSignal6":         "got signal 6",
+	"RegexGotSignal11":        "got signal 11",
+	"RegexMariaDBGotSignal6":  "got signal 6",
+	"RegexMariaDBGotSignal11": "got signal 11",
+	"RegexAssertionFailure":   "assertion failure",
+}
+
+// Summarize builds a short chronological narrative of what happened in the cluster:
+// crashes, primary component losses, state transfers, and inconsistency votes
+func Summarize(timeline types.Timeline) []SummaryEvent {
+	s := &summarizer{
+		primary:         map[string]bool{},
+		lastCtx:         map[string]types.LogCtx{},
+		conflictSeen:    map[string]bool{},
+		conflictIndexes: map[string]int{},
+	}
+	walk(timeline, s.handle)
+	s.addTransfers(Transfers(timeline))
+	s.finish(timeline)
+	return s.events
+}
+
+type summarizer struct {
+	events []SummaryEvent
+
+	primary         map[string]bool
+	everPrimary     bool
+	lastCtx         map[string]types.LogCtx
+	conflictSeen    map[string]bool
+	conflictIndexes map[string]int
+}
+
+func (s *summarizer) add(date time.Time, node string, kind SummaryKind, text string) {
+	s.events = append(s.events, SummaryEvent{Date: date, Node: node, Kind: kind, Text: text})
+}
+
+func (s *summarizer) handle(node string, li types.LogInfo) {
+	date := dateOf(li)
+	defer func() { s.lastCtx[node] = li.Ctx }()
+
+	if reason, ok := crashRegexes[li.RegexUsed]; ok {
+		s.add(date, node, SummaryCrash, node+" crashed: "+reason)
+	}
+
+	// states such as JOINED or DONOR do not tell whether the node is still in the primary component, only views do
+	switch {
+	case li.RegexUsed == "RegexNewComponent":
+		s.handleComponent(date, node, li)
+
+	// crashes, shutdowns, ...: nodes can leave the primary component without logging a new one
+	case utils.SliceContains(startRegexes, li.RegexUsed), utils.SliceContains(uncleanStopRegexes, li.RegexUsed), utils.SliceContains(cleanStopRegexes, li.RegexUsed):
+		s.primary[node] = false
+	case li.Ctx.State() == "CLOSED" || li.Ctx.State() == "DESTROYED":
+		s.primary[node] = false
+	}
+
+	for _, c := range li.Ctx.Conflicts {
+		if s.conflictSeen[c.Seqno] {
+			continue
+		}
+		s.conflictSeen[c.Seqno] = true
+		s.conflictIndexes[c.Seqno] = len(s.events)
+		s.add(date, node, SummaryConflict, "") // text is completed once every votes are known
+	}
+}
+
+func (s *summarizer) primaryCount() int {
+	count := 0
+	for _, p := range s.primary {
+		if p {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *summarizer) handleComponent(date time.Time, node string, li types.LogInfo) {
+	isPrimary := li.Ctx.State() != "NON-PRIMARY"
+	wasPrimary := s.primary[node]
+	clusterHadPrimary := s.primaryCount() > 0
+	s.primary[node] = isPrimary
+	n := li.Ctx.MemberCount
+
+	switch {
+	case isPrimary && !clusterHadPrimary && s.everPrimary:
+		s.add(date, node, SummaryPrimaryRegained, fmt.Sprintf("cluster regained a primary component with %s (n=%d)", node, n))
+	case isPrimary && !clusterHadPrimary:
+		s.add(date, node, SummaryPrimaryRegained, fmt.Sprintf("primary component formed with %s (n=%d)", node, n))
+	case isPrimary && !wasPrimary && s.lastCtx[node].State() == "NON-PRIMARY":
+		s.add(date, node, SummaryPrimaryRegained, fmt.Sprintf("%s is back in the primary component (n=%d)", node, n))
+	case !isPrimary && wasPrimary:
+		s.add(date, node, SummaryPrimaryLost, fmt.Sprintf("%s lost the primary component (n=%d)", node, n))
+		if s.primaryCount() == 0 {
+			s.add(date, "", SummaryPrimaryLost, "cluster has no primary component anymore")
+		}
+	}
+	if isPrimary {
+		s.everPrimary = true
+	}
+}
+
+// addTransfers uses the same transfers as the sst command
+func (s *summarizer) addTransfers(transfers []Transfer) {
+	for _, t := range transfers {
+		switch {
+		case t.Outcome == TransferSuccess:
+			s.add(t.End, t.Joiner, SummaryStateTransfer, transferText(t))
+		case t.Outcome == TransferFailed:
+			s.add(t.End, t.Joiner, SummaryTransferFailed, transferText(t))
+		case !t.Start.IsZero():
+			s.add(t.Start, t.Joiner, SummaryTransferFailed, fmt.Sprintf("%s requested a state transfer from %s, its end was not found", t.Joiner, t.Donor))
+		case !t.Streaming.IsZero():
+			s.add(t.Streaming, t.Joiner, SummaryTransferFailed, transferText(t)+", its end was not found")
+		}
+	}
+}
+
+func transferText(t Transfer) string {
+	transferTyp := t.Type
+	if transferTyp == "" {
+		transferTyp = "state transfer"
+	}
+	joiner := t.Joiner
+	if joiner == "" {
+		joiner = "a node that left the group"
+	}
+
+	var text string
+	switch t.Outcome {
+	case TransferSuccess:
+		text = fmt.Sprintf("%s received %s from %s", joiner, transferTyp, t.Donor)
+	case TransferFailed:
+		text = fmt.Sprintf("%s failed to receive %s from %s", joiner, transferTyp, t.Donor)
+	default:
+		text = fmt.Sprintf("%s was receiving %s from %s", joiner, transferTyp, t.Donor)
+	}
+	if t.Duration > 0 {
+		text += fmt.Sprintf(" (took %s)", t.Duration.Round(time.Second))
+	}
+	return text
+}
+
+func (s *summarizer) finish(timeline types.Timeline) {
+
+	// conflicts are shared between every contexts of a node, the latest ones have every votes
+	conflicts := types.Conflicts{}
+	for _, ctx := range timeline.GetLatestUpdatedContextsByNodes() {
+		for _, c := range ctx.Conflicts {
+			merged := types.Conflict{Seqno: c.Seqno, InitiatedBy: c.InitiatedBy, Winner: c.Winner, VotePerNode: map[string]types.ConflictVote{}}
+			for node, vote := range c.VotePerNode {
+				merged.VotePerNode[node] = vote
+			}
+			if existing := conflicts.ConflictWithSeqno(c.Seqno); existing != nil && existing.Winner == "" {
+				existing.Winner = c.Winner
+			}
+			conflicts = conflicts.Merge(merged)
+		}
+	}
+	for seqno, i := range s.conflictIndexes {
+		c := conflicts.ConflictWithSeqno(seqno)
+		if c == nil {
+			continue
+		}
+		s.events[i].Text = conflictText(c)
+	}
+
+	sort.SliceStable(s.events, func(i, j int) bool {
+		return s.events[i].Date.Before(s.events[j].Date)
+	})
+}
+
+func conflictText(c *types.Conflict) string {
+	text := "inconsistency vote on seqno " + c.Seqno
+	if len(c.InitiatedBy) > 0 {
+		text += ", initiated by " + strings.Join(c.InitiatedBy, ", ")
+	}
+	if c.Winner == "" {
+		return text + ", no winner found"
+	}
+
+	losers := []string{}
+	for node, vote := range c.VotePerNode {
+		if vote.MD5 != c.Winner {
+			losers = append(losers, node)
+		}
+	}
+	sort.Strings(losers)
+	if len(losers) == 0 {
+		return text + ", every node agreed"
+	}
+	return text + ", voted out: " + strings.Join(losers, ", ")
+}
@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+type testLine struct {
+	seconds  int
+	regexKey string
+	log      string
+}
+
+// buildLocalTimeline handles lines the same way timelineFromPaths would, without the grep/date parts
+func buildLocalTimeline(name string, lines []testLine) types.LocalTimeline {
+	regexes := regex.AllRegexes()
+	ctx := types.NewLogCtx()
+	ctx.FilePath = name + ".log"
+	ctx.OwnNames = []string{name}
+	lt := types.LocalTimeline{}
+	for _, line := range lines {
+		r := regexes[line.regexKey]
+		var displayer types.LogDisplayer
+		ctx, displayer = r.Handle(ctx, line.log)
+		date := types.NewDate(time.Date(2023, time.January, 1, 0, 0, line.seconds, 0, time.UTC), "2006-01-02T15:04:05.000000Z")
+		lt = lt.Add(types.NewLogInfo(date, displayer, line.log, r, line.regexKey, ctx, ""))
+	}
+	return lt
+}
+
+func buildTimeline(lines map[string][]testLine) types.Timeline {
+	timeline := types.Timeline{}
+	for node, l := range lines {
+		timeline[node] = buildLocalTimeline(node, l)
+	}
+	return timeline
+}
+
+func TestSummarize(t *testing.T) {
+	timeline := types.Timeline{
+		"node1": buildLocalTimeline("node1", []testLine{
+			{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 2"},
+			{10, "RegexSSTRequestSuccess", "Member 1.0 (node2) requested state transfer from '*any*'. Selected 0.0 (node1)(SYNCED) as donor."},
+			{70, "RegexSSTComplete", "0.0 (node1): State transfer to 1.0 (node2) complete."},
+			{100, "RegexNewComponent", "New COMPONENT: primary = no, bootstrap = no, my_idx = 0, memb_num = 1"},
+		}),
+		"node2": buildLocalTimeline("node2", []testLine{
+			{1, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 1, memb_num = 2"},
+			{10, "RegexSSTRequestSuccess", "Member 1.0 (node2) requested state transfer from '*any*'. Selected 0.0 (node1)(SYNCED) as donor."},
+			{11, "RegexSSTProceeding", "Proceeding with SST"},
+			{71, "RegexSSTComplete", "0.0 (node1): State transfer to 1.0 (node2) complete."},
+			{90, "RegexGotSignal11", "mysqld got signal 11 ;"},
+		}),
+	}
+
+	expected := []SummaryEvent{
+		{Node: "node1", Kind: SummaryPrimaryRegained, Text: "primary component formed with node1 (n=2)"},
+		{Node: "node2", Kind: SummaryStateTransfer, Text: "node2 received SST from node1 (took 1m0s)"},
+		{Node: "node2", Kind: SummaryCrash, Text: "node2 crashed: got signal 11"},
+		{Node: "node1", Kind: SummaryPrimaryLost, Text: "node1 lost the primary component (n=1)"},
+		{Node: "", Kind: SummaryPrimaryLost, Text: "cluster has no primary component anymore"},
+	}
+
+	events := Summarize(timeline)
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(events), events)
+	}
+	for i := range expected {
+		if events[i].Node != expected[i].Node || events[i].Kind != expected[i].Kind || events[i].Text != expected[i].Text {
+			t.Errorf("event %d: expected %v, got %v", i, expected[i], events[i])
+		}
+	}
+
+	// the timeline should still be usable afterward
+	if len(timeline["node1"]) != 4 {
+		t.Errorf("timeline was modified")
+	}
+}
+
+// states logged between views should not change whether the node is known to be in the primary component
+func TestSummarizePrimaryLostAfterDonor(t *testing.T) {
+	timeline := types.Timeline{
+		"node1": buildLocalTimeline("node1", []testLine{
+			{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 2"},
+			{10, "RegexShift", "Shifting SYNCED -> DONOR/DESYNCED (TO: 10)"},
+			{70, "RegexShift", "Shifting DONOR/DESYNCED -> JOINED (TO: 10)"},
+			{100, "RegexNewComponent", "New COMPONENT: primary = no, bootstrap = no, my_idx = 0, memb_num = 1"},
+		}),
+	}
+
+	expected := []SummaryEvent{
+		{Node: "node1", Kind: SummaryPrimaryRegained, Text: "primary component formed with node1 (n=2)"},
+		{Node: "node1", Kind: SummaryPrimaryLost, Text: "node1 lost the primary component (n=1)"},
+		{Node: "", Kind: SummaryPrimaryLost, Text: "cluster has no primary component anymore"},
+	}
+
+	events := Summarize(timeline)
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(events), events)
+	}
+	for i := range expected {
+		if events[i].Node != expected[i].Node || events[i].Kind != expected[i].Kind || events[i].Text != expected[i].Text {
+			t.Errorf("event %d: expected %v, got %v", i, expected[i], events[i])
+		}
+	}
+}
+
+// the summary should report the same transfers as the sst command
+func TestSummarizeTransfers(t *testing.T) {
+	timeline := buildTimeline(map[string][]testLine{
+		"node1": {
+			{200, "RegexSSTRequestSuccess", "Member 2.0 (node3) requested state transfer from '*any*'. Selected 0.0 (node1)(SYNCED) as donor."},
+			{201, "RegexISTSender", "IST sender starting to serve tcp://172.17.0.4:4568 sending 90-100"},
+			{205, "RegexISTFailed", "async IST sender failed to serve tcp://172.17.0.4:4568: ist send failed: asio.system:104', asio error 'write: Connection reset by peer': 104 (Connection reset by peer)"},
+		},
+		"node2": {
+			{200, "RegexSSTRequestSuccess", "Member 2.0 (node3) requested state transfer from '*any*'. Selected 0.0 (node1)(SYNCED) as donor."},
+			{300, "RegexSSTRequestSuccess", "Member 2.0 (node3) requested state transfer from '*any*'. Selected 1.0 (node2)(SYNCED) as donor."},
+		},
+	})
+
+	expected := []SummaryEvent{
+		{Node: "node3", Kind: SummaryTransferFailed, Text: "node3 failed to receive IST from node1 (took 5s)"},
+		{Node: "node3", Kind: SummaryTransferFailed, Text: "node3 requested a state transfer from node2, its end was not found"},
+	}
+
+	events := Summarize(timeline)
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(events), events)
+	}
+	for i := range expected {
+		if events[i].Node != expected[i].Node || events[i].Kind != expected[i].Kind || events[i].Text != expected[i].Text {
+			t.Errorf("event %d: expected %v, got %v", i, expected[i], events[i])
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package analysis
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// TransferOutcome is how a state transfer ended
+type TransferOutcome string
+
+const (
+	TransferSuccess TransferOutcome = "success"
+	TransferFailed  TransferOutcome = "failed"
+
+	// TransferUnfinished is used when the end of the transfer was not found in the logs
+	TransferUnfinished TransferOutcome = "unfinished"
+)
+
+// Transfer is a single state transfer, from its request to its end, as seen from every log that mentioned it
+type Transfer struct {
+	Type   string `json:"type,omitempty"`   // SST or IST, empty when the logs did not tell
+	Method string `json:"method,omitempty"` // SST script, when logged
+	Joiner string `json:"joiner"`
+	Donor  string `json:"donor"`
+
+	// Start is when the transfer was requested, Streaming when the donor started to send data
+	// they are zero when not found, such as for transfers started before the logs
+	Start     time.Time     `json:"start"`
+	Streaming time.Time     `json:"streaming,omitempty"`
+	End       time.Time     `json:"end"`
+	Duration  time.Duration `json:"duration,omitempty"`
+
+	Outcome TransferOutcome `json:"outcome"`
+	Error   string          `json:"error,omitempty"`
+
+	// ReportedBy are the nodes whose logs had a line about this transfer
+	ReportedBy []string `json:"reportedBy"`
+}
+
+// Every node involved logs the same state transfer lines, so those are deduplicated when seen again within this window
+const transferDedupWindow = time.Minute
+
+// Transfers rebuilds every state transfer from the request to the completion or failure
+// Every member logs the requests and completions, the joiner and donor logs add the type, method, and failures
+func Transfers(timeline types.Timeline) []Transfer {
+	tracker := &transferTracker{pending: map[string]*Transfer{}, lastCtx: map[string]types.LogCtx{}}
+	walk(timeline, tracker.handle)
+
+	transfers := make([]Transfer, 0, len(tracker.transfers))
+	for _, t := range tracker.transfers {
+		if t.Outcome == "" {
+			t.Outcome = TransferUnfinished
+		}
+		if !t.Start.IsZero() && !t.End.IsZero() {
+			t.Duration = t.End.Sub(t.Start)
+		}
+		sort.Strings(t.ReportedBy)
+		transfers = append(transfers, *t)
+	}
+	sort.SliceStable(transfers, func(i, j int) bool {
+		return transfers[i].sortDate().Before(transfers[j].sortDate())
+	})
+	return transfers
+}
+
+func (t Transfer) sortDate() time.Time {
+	if t.Start.IsZero() {
+		return t.End
+	}
+	return t.Start
+}
+
+type transferTracker struct {
+	transfers []*Transfer
+	pending   map[string]*Transfer // per joiner
+	lastCtx   map[string]types.LogCtx
+}
+
+func (tr *transferTracker) handle(node string, li types.LogInfo) {
+	date := dateOf(li)
+	defer func() { tr.lastCtx[node] = li.Ctx }()
+
+	switch li.RegexUsed {
+	case "RegexSSTRequestSuccess":
+		submatches := regex.SSTMap[li.RegexUsed].Submatches(li.Log)
+		if submatches == nil {
+			return
+		}
+		tr.request(node, date, utils.ShortNodeName(submatches[regex.GroupNodeName]), utils.ShortNodeName(submatches[regex.GroupNodeName2]))
+
+	case "RegexSSTStreamingTo", "RegexISTSender":
+		t := tr.fromDonor(node, li)
+		if li.RegexUsed == "RegexSSTStreamingTo" {
+			t.Type = "SST"
+		} else {
+			t.Type = "IST"
+		}
+		if t.Streaming.IsZero() {
+			t.Streaming = date
+		}
+
+	case "RegexSSTProceeding", "RegexISTReceiver", "RegexISTReceived", "RegexFailedToPrepareIST", "RegexBypassSST":
+		t := tr.fromJoiner(node, li)
+		if li.Ctx.SST.Type != "" {
+			t.Type = li.Ctx.SST.Type
+		}
+
+	case "RegexSSTComplete", "RegexSSTStateTransferFailed":
+		submatches := regex.SSTMap[li.RegexUsed].Submatches(li.Log)
+		if submatches == nil {
+			return
+		}
+		donor := utils.ShortNodeName(submatches[regex.GroupNodeName])
+		joiner := utils.ShortNodeName(submatches[regex.GroupNodeName2])
+		if li.RegexUsed == "RegexSSTComplete" {
+			tr.end(node, date, joiner, donor, TransferSuccess, "")
+		} else {
+			tr.end(node, date, joiner, donor, TransferFailed, afterLast(li.Log, "failed:"))
+		}
+
+	case "RegexSSTCompleteUnknown", "RegexSSTFailedUnknown":
+		// the joiner left the group: only the donor is known
+		submatches := regex.SSTMap[li.RegexUsed].Submatches(li.Log)
+		if submatches == nil {
+			return
+		}
+		donor := utils.ShortNodeName(submatches[regex.GroupNodeName])
+		joiner := ""
+		if t := tr.pendingWith(func(t *Transfer) bool { return t.Donor == donor }); t != nil {
+			joiner = t.Joiner
+		}
+		if li.RegexUsed == "RegexSSTCompleteUnknown" {
+			tr.end(node, date, joiner, donor, TransferSuccess, "")
+		} else {
+			transferError := "joiner left the group"
+			if e := afterLast(li.Log, "failed:"); e != "" {
+				transferError += ": " + e
+			}
+			tr.end(node, date, joiner, donor, TransferFailed, transferError)
+		}
+
+	case "RegexISTFailed":
+		t := tr.fromDonor(node, li)
+		t.Type = "IST"
+		istError := ""
+		if submatches := regex.SSTMap[li.RegexUsed].Submatches(li.Log); submatches != nil {
+			istError = submatches["error"]
+		}
+		tr.end(node, date, t.Joiner, t.Donor, TransferFailed, istError)
+
+	case "RegexSSTError":
+		// the failure itself is logged right after by every member
+		if t := tr.involving(node, li); t != nil && t.Error == "" {
+			t.Error = afterLast(li.Log, ": ")
+		}
+	}
+
+	if li.Ctx.SST.Method != "" && li.Ctx.SST.Method != tr.lastCtx[node].SST.Method {
+		if t := tr.involving(node, li); t != nil && t.Method == "" {
+			t.Method = li.Ctx.SST.Method
+		}
+	}
+}
+
+func (tr *transferTracker) request(node string, date time.Time, joiner, donor string) {
+	if t, ok := tr.pending[joiner]; ok {
+		if t.Donor == donor && date.Sub(t.Start) < transferDedupWindow {
+			t.reportedBy(node)
+			return
+		}
+		// a new request replaces the previous one, which end was never found
+		delete(tr.pending, joiner)
+	}
+	tr.open(&Transfer{Joiner: joiner, Donor: donor, Start: date}, node)
+}
+
+func (tr *transferTracker) open(t *Transfer, node string) *Transfer {
+	t.reportedBy(node)
+	tr.transfers = append(tr.transfers, t)
+	tr.pending[t.Joiner] = t
+	return t
+}
+
+// end uses the state transfer type as known by the node before the line was handled: it is reset right after
+func (tr *transferTracker) end(node string, date time.Time, joiner, donor string, outcome TransferOutcome, transferError string) {
+	transferTyp := tr.lastCtx[node].SST.Type
+
+	t, ok := tr.pending[joiner]
+	if !ok || t.Donor != donor {
+		// the joiner may only have been known by its IP
+		t = tr.pendingWith(func(t *Transfer) bool { return t.Donor == donor && net.ParseIP(t.Joiner) != nil })
+	}
+	if t == nil {
+		t = tr.recentlyEnded(date, joiner, donor)
+		if t != nil {
+			// another node logged the same one, it can still know it better
+			t.reportedBy(node)
+			if t.Type == "" {
+				t.Type = transferTyp
+			}
+			if t.Error == "" {
+				t.Error = transferError
+			}
+			return
+		}
+		t = tr.open(&Transfer{Joiner: joiner, Donor: donor}, node)
+	}
+
+	delete(tr.pending, t.Joiner)
+	if joiner != "" {
+		t.Joiner = joiner
+	}
+	t.reportedBy(node)
+	t.End = date
+	t.Outcome = outcome
+	if t.Type == "" {
+		t.Type = transferTyp
+	}
+	if transferError != "" {
+		t.Error = transferError
+	}
+}
+
+func (tr *transferTracker) recentlyEnded(date time.Time, joiner, donor string) *Transfer {
+	for i := len(tr.transfers) - 1; i >= 0; i-- {
+		t := tr.transfers[i]
+		if t.End.IsZero() || date.Sub(t.End) >= transferDedupWindow {
+			continue
+		}
+		if t.Donor == donor && (t.Joiner == joiner || joiner == "") {
+			return t
+		}
+	}
+	return nil
+}
+
+// pendingWith returns the latest pending transfer matching f
+func (tr *transferTracker) pendingWith(f func(*Transfer) bool) *Transfer {
+	var found *Transfer
+	for _, t := range tr.pending {
+		if f(t) && (found == nil || t.Start.After(found.Start)) {
+			found = t
+		}
+	}
+	return found
+}
+
+func (tr *transferTracker) involving(node string, li types.LogInfo) *Transfer {
+	names := append([]string{node}, li.Ctx.OwnNames...)
+	return tr.pendingWith(func(t *Transfer) bool {
+		return utils.SliceContains(names, t.Joiner) || utils.SliceContains(names, t.Donor)
+	})
+}
+
+// fromDonor finds the transfer the node is the donor of, or opens one when its request was not found
+func (tr *transferTracker) fromDonor(node string, li types.LogInfo) *Transfer {
+	if t, ok := tr.pending[li.Ctx.SST.ResyncingNode]; ok {
+		t.reportedBy(node)
+		return t
+	}
+	names := append([]string{node}, li.Ctx.OwnNames...)
+	if t := tr.pendingWith(func(t *Transfer) bool { return utils.SliceContains(names, t.Donor) }); t != nil {
+		t.reportedBy(node)
+		return t
+	}
+
+	joiner := li.Ctx.SST.ResyncingNode
+	if submatches := regex.SSTMap[li.RegexUsed].Submatches(li.Log); joiner == "" && submatches != nil {
+		joiner = submatches[regex.GroupNodeIP]
+	}
+	if name, ok := li.Ctx.IPToNodeName[joiner]; ok {
+		joiner = name
+	}
+	return tr.open(&Transfer{Joiner: joiner, Donor: ownName(node, li.Ctx)}, node)
+}
+
+// fromJoiner finds the transfer the node is the joiner of, or opens one when its request was not found
+func (tr *transferTracker) fromJoiner(node string, li types.LogInfo) *Transfer {
+	names := append([]string{node}, li.Ctx.OwnNames...)
+	if t := tr.pendingWith(func(t *Transfer) bool { return utils.SliceContains(names, t.Joiner) }); t != nil {
+		t.reportedBy(node)
+		return t
+	}
+	return tr.open(&Transfer{Joiner: ownName(node, li.Ctx), Donor: li.Ctx.SST.ResyncedFromNode}, node)
+}
+
+func (t *Transfer) reportedBy(node string) {
+	if !utils.SliceContains(t.ReportedBy, node) {
+		t.ReportedBy = append(t.ReportedBy, node)
+	}
+}
+
+func ownName(node string, ctx types.LogCtx) string {
+	if len(ctx.OwnNames) > 0 {
+		return ctx.OwnNames[len(ctx.OwnNames)-1]
+	}
+	return node
+}
+
+func afterLast(s, sep string) string {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(s[i+len(sep):])
+}
@@ -0,0 +1,53 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTransfers(t *testing.T) {
+	lines := map[string][]testLine{
+		"node1": {
+			{10, "RegexSSTRequestSuccess", "Member 1.0 (node2) requested state transfer from '*any*'. Selected 0.0 (node1)(SYNCED) as donor."},
+			{12, "RegexSSTStreamingTo", "Streaming the backup to joiner at 172.17.0.3 4444"},
+			{70, "RegexSSTComplete", "0.0 (node1): State transfer to 1.0 (node2) complete."},
+			{200, "RegexSSTRequestSuccess", "Member 2.0 (node3) requested state transfer from '*any*'. Selected 0.0 (node1)(SYNCED) as donor."},
+			{201, "RegexISTSender", "IST sender starting to serve tcp://172.17.0.4:4568 sending 90-100"},
+			{205, "RegexISTFailed", "async IST sender failed to serve tcp://172.17.0.4:4568: ist send failed: asio.system:104', asio error 'write: Connection reset by peer': 104 (Connection reset by peer)"},
+		},
+		"node2": {
+			{10, "RegexSSTRequestSuccess", "Member 1.0 (node2) requested state transfer from '*any*'. Selected 0.0 (node1)(SYNCED) as donor."},
+			{11, "RegexSSTProceeding", "Proceeding with SST"},
+			{71, "RegexSSTComplete", "0.0 (node1): State transfer to 1.0 (node2) complete."},
+			{200, "RegexSSTRequestSuccess", "Member 2.0 (node3) requested state transfer from '*any*'. Selected 0.0 (node1)(SYNCED) as donor."},
+			{300, "RegexSSTRequestSuccess", "Member 2.0 (node3) requested state transfer from '*any*'. Selected 1.0 (node2)(SYNCED) as donor."},
+		},
+	}
+
+	transfers := Transfers(buildTimeline(lines))
+	if len(transfers) != 3 {
+		t.Fatalf("expected 3 transfers, got %d: %+v", len(transfers), transfers)
+	}
+
+	sst := transfers[0]
+	if sst.Joiner != "node2" || sst.Donor != "node1" || sst.Type != "SST" || sst.Outcome != TransferSuccess {
+		t.Errorf("unexpected sst: %+v", sst)
+	}
+	if sst.Duration != 60*time.Second || sst.Streaming.Second() != 12 {
+		t.Errorf("unexpected sst dates: %+v", sst)
+	}
+	if !reflect.DeepEqual(sst.ReportedBy, []string{"node1", "node2"}) {
+		t.Errorf("unexpected sst reporters: %v", sst.ReportedBy)
+	}
+
+	ist := transfers[1]
+	if ist.Joiner != "node3" || ist.Type != "IST" || ist.Outcome != TransferFailed || ist.Error != "Connection reset by peer" || ist.Duration != 5*time.Second {
+		t.Errorf("unexpected ist: %+v", ist)
+	}
+
+	unfinished := transfers[2]
+	if unfinished.Joiner != "node3" || unfinished.Donor != "node2" || unfinished.Outcome != TransferUnfinished || !unfinished.End.IsZero() {
+		t.Errorf("unexpected unfinished transfer: %+v", unfinished)
+	}
+}
@@ -0,0 +1,238 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// Members of a component log the same view almost at once, views seen by different nodes are merged within this window
+const viewMergeWindow = 10 * time.Second
+
+// ClusterView is a component installed in the cluster, as seen by one or more nodes
+type ClusterView struct {
+	Date        time.Time `json:"date"` // earliest date it was logged at
+	Primary     bool      `json:"primary"`
+	MemberCount int       `json:"memberCount"`
+
+	// Members are sorted node names, or IPs and hashes when their names were not found
+	// it is empty when the logs did not tell who the members were
+	Members []string `json:"members,omitempty"`
+
+	// SeenBy are the nodes whose logs had this view
+	SeenBy []string `json:"seenBy"`
+}
+
+// Views rebuilds the sequence of cluster views
+// Each node keeps track of members joining and leaving, the member lists logged along views are used when available
+// when neither explains the member count, nodes it connected to and did not suspect are used
+func Views(timeline types.Timeline) []ClusterView {
+	views, _ := buildViews(timeline)
+
+	result := make([]ClusterView, 0, len(views))
+	for _, v := range views {
+		result = append(result, *v)
+	}
+	return result
+}
+
+// nodeView is a view as installed by a node, at its own date
+type nodeView struct {
+	date time.Time
+	view *ClusterView
+}
+
+// buildViews also returns, per node, the merged views it installed
+func buildViews(timeline types.Timeline) ([]*ClusterView, map[string][]nodeView) {
+	perNode := []ClusterView{}
+	for node, lt := range timeline {
+		perNode = append(perNode, nodeViews(node, lt)...)
+	}
+	sort.SliceStable(perNode, func(i, j int) bool {
+		if perNode[i].Date.Equal(perNode[j].Date) {
+			return perNode[i].SeenBy[0] < perNode[j].SeenBy[0]
+		}
+		return perNode[i].Date.Before(perNode[j].Date)
+	})
+
+	views := []*ClusterView{}
+	installed := map[string][]nodeView{}
+	for i := range perNode {
+		v := &perNode[i]
+		node := v.SeenBy[0]
+		if merged := sameView(views, v); merged != nil {
+			merged.SeenBy = append(merged.SeenBy, node)
+			if len(merged.Members) == 0 {
+				merged.Members = v.Members
+			}
+			installed[node] = append(installed[node], nodeView{date: v.Date, view: merged})
+			continue
+		}
+		views = append(views, v)
+		installed[node] = append(installed[node], nodeView{date: v.Date, view: v})
+	}
+
+	for _, v := range views {
+		sort.Strings(v.SeenBy)
+	}
+	return views, installed
+}
+
+// sameView finds a view from another node that was installed at the same time with the same members
+func sameView(views []*ClusterView, v *ClusterView) *ClusterView {
+	for i := len(views) - 1; i >= 0; i-- {
+		candidate := views[i]
+		if v.Date.Sub(candidate.Date) > viewMergeWindow {
+			break
+		}
+		if candidate.Primary != v.Primary || candidate.MemberCount != v.MemberCount || utils.SliceContains(candidate.SeenBy, v.SeenBy[0]) {
+			continue
+		}
+		if len(candidate.Members) > 0 && len(v.Members) > 0 && strings.Join(candidate.Members, ",") != strings.Join(v.Members, ",") {
+			continue
+		}
+		return candidate
+	}
+	return nil
+}
+
+// nodeViews lists the views a single node installed, with the hashes of their members
+func nodeViews(node string, lt types.LocalTimeline) []ClusterView {
+	if len(lt) == 0 {
+		return nil
+	}
+
+	views := []ClusterView{}
+	hashes := [][]string{}
+	fallbacks := [][]string{}        // used when joins and leaves do not explain the member count
+	members := map[string]bool{}     // hashes of the other members, from joins and leaves
+	established := map[string]bool{} // hashes of the nodes it connected to, members are not always declared stable in the logs
+	suspected := map[string]bool{}
+	fromAssociations := false
+
+	for _, li := range lt {
+		switch {
+		case utils.SliceContains(startRegexes, li.RegexUsed):
+			members = map[string]bool{}
+			established = map[string]bool{}
+			suspected = map[string]bool{}
+
+		case li.RegexUsed == "RegexNodeJoined" || li.RegexUsed == "RegexNodeLeft" || li.RegexUsed == "RegexNodeEstablished" || li.RegexUsed == "RegexNodeSuspect":
+			submatches := regex.ViewsMap[li.RegexUsed].Submatches(li.Log)
+			if submatches == nil {
+				continue
+			}
+			hash := submatches[regex.GroupNodeHash]
+			switch li.RegexUsed {
+			case "RegexNodeJoined":
+				members[hash] = true
+			case "RegexNodeLeft":
+				delete(members, hash)
+				delete(established, hash)
+			case "RegexNodeEstablished":
+				if utils.SliceContains(li.Ctx.OwnIPs, submatches[regex.GroupNodeIP]) {
+					continue
+				}
+				established[hash] = true
+				delete(suspected, hash)
+			case "RegexNodeSuspect":
+				suspected[hash] = true
+			}
+
+		case li.RegexUsed == "RegexNewComponent":
+			views = append(views, ClusterView{Date: dateOf(li), Primary: li.Ctx.State() != "NON-PRIMARY", MemberCount: li.Ctx.MemberCount, SeenBy: []string{node}})
+			h := []string{""} // the node itself, its hash is not always known
+			for hash := range members {
+				h = append(h, hash)
+			}
+			hashes = append(hashes, h)
+
+			// suspected nodes are usually the ones that were left out of the view
+			f := []string{""}
+			for hash := range members {
+				if !suspected[hash] {
+					f = append(f, hash)
+				}
+			}
+			for hash := range established {
+				if suspected[hash] {
+					delete(established, hash)
+				} else if !members[hash] {
+					f = append(f, hash)
+				}
+			}
+			fallbacks = append(fallbacks, f)
+			suspected = map[string]bool{}
+			fromAssociations = false
+
+		// the member list is logged right after the component, it replaces what was guessed from joins and leaves
+		case li.RegexUsed == "RegexMemberAssociations" && len(views) > 0:
+			submatches := regex.IdentsMap[li.RegexUsed].Submatches(li.Log)
+			if submatches == nil {
+				continue
+			}
+			last := len(hashes) - 1
+			if !fromAssociations {
+				hashes[last] = []string{}
+				fallbacks[last] = nil
+				members = map[string]bool{}
+				fromAssociations = true
+			}
+			hash := utils.UUIDToShortUUID(submatches[regex.GroupUUID])
+			hashes[last] = append(hashes[last], hash)
+			members[hash] = true
+		}
+	}
+
+	// the latest context knows the most names
+	ctx := lt[len(lt)-1].Ctx
+	for i := range views {
+		if views[i].MemberCount == 1 {
+			views[i].Members = []string{ownName(node, ctx)}
+			continue
+		}
+
+		names := memberNames(node, hashes[i], ctx)
+		if len(names) != views[i].MemberCount && fallbacks[i] != nil {
+			names = memberNames(node, fallbacks[i], ctx)
+		}
+		if len(names) != views[i].MemberCount {
+			continue
+		}
+		sort.Strings(names)
+		views[i].Members = names
+	}
+	return views
+}
+
+// memberNames deduplicates names: the node itself can be listed twice, with its hash and as itself
+func memberNames(node string, hashes []string, ctx types.LogCtx) []string {
+	names := []string{}
+	for _, hash := range hashes {
+		name := ownName(node, ctx)
+		if hash != "" && !utils.SliceContains(ctx.OwnHashes, hash) {
+			name = memberName(hash, ctx)
+		}
+		if !utils.SliceContains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func memberName(hash string, ctx types.LogCtx) string {
+	if name, ok := ctx.HashToNodeName[hash]; ok {
+		return name
+	}
+	if ip, ok := ctx.HashToIP[hash]; ok {
+		if name, ok := ctx.IPToNodeName[ip]; ok {
+			return name
+		}
+		return ip
+	}
+	return hash
+}
@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestViews(t *testing.T) {
+	lines := map[string][]testLine{
+		"node1": {
+			{0, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 3"},
+			{0, "RegexMemberAssociations", "	0: 015702fc-32f5-11ed-a4ca-267f97316394, node1"},
+			{0, "RegexMemberAssociations", "	1: 08dd5580-32f7-11ed-a9eb-af5e3d01519e, node2"},
+			{0, "RegexMemberAssociations", "	2: 0dc0d09b-32f7-11ed-9b40-af5e3d01519e, node3"},
+			{50, "RegexNodeLeft", "forgetting 0dc0d09b-9b40 (tcp://10.0.0.3:4567)"},
+			{50, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 2"},
+		},
+		"node2": {
+			{1, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 1, memb_num = 3"},
+			{51, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 1, memb_num = 2"},
+		},
+		"node3": {
+			{1, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 2, memb_num = 3"},
+			{49, "RegexNewComponent", "New COMPONENT: primary = no, bootstrap = no, my_idx = 0, memb_num = 1"},
+		},
+	}
+
+	views := Views(buildTimeline(lines))
+
+	expected := []ClusterView{
+		{Primary: true, MemberCount: 3, Members: []string{"node1", "node2", "node3"}, SeenBy: []string{"node1", "node2", "node3"}},
+		{Primary: false, MemberCount: 1, Members: []string{"node3"}, SeenBy: []string{"node3"}},
+		{Primary: true, MemberCount: 2, Members: []string{"node1", "node2"}, SeenBy: []string{"node1", "node2"}},
+	}
+	if len(views) != len(expected) {
+		t.Fatalf("expected %d views, got %d: %+v", len(expected), len(views), views)
+	}
+	for i := range expected {
+		views[i].Date = expected[i].Date
+		if !reflect.DeepEqual(views[i], expected[i]) {
+			t.Errorf("view %d: expected %+v, got %+v", i, expected[i], views[i])
+		}
+	}
+}
+
+func TestViewsFromEstablishedConnections(t *testing.T) {
+	lines := map[string][]testLine{
+		"node1": {
+			{0, "RegexNodeEstablished", "(015702fc-a4ca, 'tcp://0.0.0.0:4567') connection established to 08dd5580-a9eb tcp://10.0.0.2:4567"},
+			{0, "RegexNodeEstablished", "(015702fc-a4ca, 'tcp://0.0.0.0:4567') connection established to 0dc0d09b-9b40 tcp://10.0.0.3:4567"},
+			{1, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 3"},
+			{40, "RegexNodeSuspect", "evs::proto(015702fc-a4ca, OPERATIONAL, view_id(REG,015702fc-a4ca,3)) suspecting node: 0dc0d09b-9b40"},
+			{50, "RegexNewComponent", "New COMPONENT: primary = yes, bootstrap = no, my_idx = 0, memb_num = 2"},
+		},
+	}
+
+	views := Views(buildTimeline(lines))
+
+	expected := []ClusterView{
+		{Primary: true, MemberCount: 3, Members: []string{"10.0.0.2", "10.0.0.3", "node1"}, SeenBy: []string{"node1"}},
+		{Primary: true, MemberCount: 2, Members: []string{"10.0.0.2", "node1"}, SeenBy: []string{"node1"}},
+	}
+	if len(views) != len(expected) {
+		t.Fatalf("expected %d views, got %d: %+v", len(expected), len(views), views)
+	}
+	for i := range expected {
+		views[i].Date = expected[i].Date
+		if !reflect.DeepEqual(views[i], expected[i]) {
+			t.Errorf("view %d: expected %+v, got %+v", i, expected[i], views[i])
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+type anonymize struct {
+	Paths     []string `arg:"" optional:"" name:"paths" help:"paths of the log to use"`
+	OutputDir string   `help:"Directory to write anonymized copies of the logs to"`
+	Snapshot  string   `help:"File to write an anonymized snapshot of the timeline to, to render with 'list --from-snapshot'"`
+	KeepLogs  bool     `help:"Keep anonymized raw log lines in the snapshot"`
+	Mapping   string   `default:"anonymize-mapping.json" help:"File keeping pseudonyms, to reuse them in later runs and to reverse them. It should not be shared"`
+	Reverse   bool     `help:"Translates stdin back to the original identifiers using the mapping file"`
+}
+
+func (a *anonymize) Help() string {
+	return `anonymize replaces every IP, hostname, node name and UUID known from the logs by pseudonyms, the same way across every file
+Errors from inconsistency votes are also replaced, as they can contain schemas, tables and data
+Every directory and file name in paths is replaced as well, only extensions are kept
+Pseudonyms are kept in the mapping file: later runs reuse them, and --reverse can translate them back
+
+Usage:
+	galera-log-explainer anonymize --output-dir anonymized/ *.log
+	galera-log-explainer anonymize --snapshot anonymized.json *.log
+	galera-log-explainer anonymize --reverse < message-from-someone.txt
+
+Only identifiers this tool could find are replaced, anonymized logs should still be reviewed before being shared`
+}
+
+func (a *anonymize) checkFlags() error {
+	if a.Reverse {
+		if len(a.Paths) > 0 || a.OutputDir != "" || a.Snapshot != "" {
+			return errors.New("--reverse only reads stdin, it can't be used with paths, --output-dir or --snapshot")
+		}
+		return nil
+	}
+	if len(a.Paths) == 0 {
+		return errors.New("Please give paths of logs to anonymize")
+	}
+	if a.OutputDir == "" && a.Snapshot == "" {
+		return errors.New("--output-dir and/or --snapshot is required")
+	}
+	if a.OutputDir != "" && utils.SliceContains(a.Paths, "-") {
+		return errors.New("stdin can't be used with --output-dir")
+	}
+	return nil
+}
+
+func (a *anonymize) Run() error {
+	if err := a.checkFlags(); err != nil {
+		return err
+	}
+
+	pseudonyms, err := loadMapping(a.Mapping)
+	if err != nil {
+		return err
+	}
+
+	if a.Reverse {
+		return translateLines(os.Stdin, os.Stdout, pseudonyms.Reverse)
+	}
+
+	timeline, err := timelineFromPaths(a.Paths, regex.AllRegexes())
+	if err != nil {
+		return errors.Wrap(err, "Found nothing to anonymize")
+	}
+	pseudonyms.AddFromContexts(timeline.GetLatestUpdatedContextsByNodes())
+
+	// saved first, so that pseudonyms are never lost
+	if err := saveMapping(a.Mapping, pseudonyms); err != nil {
+		return err
+	}
+
+	if a.OutputDir != "" {
+		if err := a.anonymizeLogs(pseudonyms); err != nil {
+			return err
+		}
+	}
+
+	if a.Snapshot != "" {
+		if !a.KeepLogs {
+			for _, lt := range timeline {
+				for i := range lt {
+					lt[i].Log = ""
+				}
+			}
+		}
+		if err := writeSnapshot(a.Snapshot, pseudonyms.AnonymizeTimeline(timeline), !a.KeepLogs); err != nil {
+			return err
+		}
+	}
+
+	// full UUIDs found while replacing are added to the mapping
+	return saveMapping(a.Mapping, pseudonyms)
+}
+
+// anonymizeLogs writes each source to the output dir, decompressed
+// names are anonymized too, they often contain hostnames
+func (a *anonymize) anonymizeLogs(pseudonyms *types.Pseudonyms) error {
+	if err := os.MkdirAll(a.OutputDir, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create output directory")
+	}
+
+	for _, source := range expandPaths(a.Paths) {
+		name := filepath.ToSlash(source.path)
+		for _, ext := range []string{".gz", ".bz2", ".zst"} {
+			name = strings.TrimSuffix(name, ext)
+		}
+		// components are anonymized one by one, identifiers would be part of a longer word once joined
+		components := strings.FieldsFunc(pseudonyms.ReplacePath(name), func(r rune) bool { return r == '/' || r == ':' })
+		name = filepath.Join(a.OutputDir, strings.Join(components, "_"))
+
+		if err := anonymizeSource(source, name, pseudonyms); err != nil {
+			return errors.Wrapf(err, "failed to anonymize %s", source.path)
+		}
+		logger.Debug().Str("path", source.path).Str("output", name).Msg("anonymized")
+	}
+	return nil
+}
+
+func anonymizeSource(source logSource, output string, pseudonyms *types.Pseudonyms) error {
+	r, err := source.open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	err = translateLines(r, f, pseudonyms.Replace)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func translateLines(r io.Reader, w io.Writer, translate func(string) string) error {
+	bw := bufio.NewWriter(w)
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			if _, werr := bw.WriteString(translate(line)); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// loadMapping starts from scratch when the mapping file does not exist yet
+func loadMapping(path string) (*types.Pseudonyms, error) {
+	pseudonyms := types.NewPseudonyms()
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return pseudonyms, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read mapping")
+	}
+	if err := json.Unmarshal(content, pseudonyms); err != nil {
+		return nil, errors.Wrapf(err, "failed to read mapping %s", path)
+	}
+	return pseudonyms, nil
+}
+
+// the mapping gives back every identifier, it is only readable by its owner
+func saveMapping(path string, pseudonyms *types.Pseudonyms) error {
+	content, err := json.MarshalIndent(pseudonyms, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize mapping")
+	}
+	return errors.Wrap(os.WriteFile(path, content, 0o600), "failed to write mapping")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnonymize(t *testing.T) {
+	testCLI(t)
+
+	dir := t.TempDir()
+	a := anonymize{
+		Paths:     []string{"testdata/db1.example.com/172.17.0.2.log", "testdata/db2.example.com/172.17.0.3.log"},
+		OutputDir: filepath.Join(dir, "logs"),
+		Snapshot:  filepath.Join(dir, "snapshot.json"),
+		KeepLogs:  true,
+		Mapping:   filepath.Join(dir, "mapping.json"),
+	}
+	if err := a.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	pseudonyms, err := loadMapping(a.Mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pseudonyms.IPs) == 0 || len(pseudonyms.NodeNames) == 0 || len(pseudonyms.UUIDs) == 0 {
+		t.Fatalf("identifiers are missing from the mapping: %+v", pseudonyms)
+	}
+	// directories are not known as identifiers from the logs, they can still be hostnames
+	originals := []string{"db1.example.com", "db2.example.com", "172.17.0.2", "172.17.0.3", "testdata"}
+	for _, m := range []map[string]string{pseudonyms.IPs, pseudonyms.UUIDs, pseudonyms.Hostnames, pseudonyms.NodeNames} {
+		for original := range m {
+			originals = append(originals, original)
+		}
+	}
+
+	// every file written, and their names
+	outputs := []string{a.Snapshot}
+	entries, err := os.ReadDir(a.OutputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		outputs = append(outputs, filepath.Join(a.OutputDir, entry.Name()))
+	}
+	if len(outputs) != 3 {
+		t.Fatalf("expected 2 anonymized logs and a snapshot, got %v", outputs)
+	}
+
+	for _, output := range outputs {
+		content, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, original := range originals {
+			if strings.Contains(string(content), original) || strings.Contains(filepath.Base(output), original) {
+				t.Errorf("%s was found in %s", original, output)
+			}
+		}
+	}
+}
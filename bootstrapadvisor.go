@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/analysis"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+type bootstrapAdvisor struct {
+	Paths []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Json  bool     `help:"Print the advice as json"`
+}
+
+func (b *bootstrapAdvisor) Help() string {
+	return `Recommend the node to bootstrap from after a full cluster outage, with the evidence found for each node
+
+Usage:
+	galera-log-explainer bootstrap-advisor *.log
+	galera-log-explainer bootstrap-advisor --until 2023-01-05T04:00:00Z *.log   # for an earlier outage
+
+It uses the last known seqnos, safe_to_bootstrap, missing grastate.dat, wsrep recoveries and the last primary components
+Confidence is lowered when the evidence conflicts, or when positions could not be confirmed by a recovery or a clean shutdown
+What the logs did not tell is shown as unknown`
+}
+
+func (b *bootstrapAdvisor) Run() error {
+
+	timeline, err := timelineFromPaths(b.Paths, regex.AllRegexes())
+	if err != nil {
+		return errors.Wrap(err, "Could not advise a node to bootstrap")
+	}
+
+	advice := analysis.AdviseBootstrap(timeline)
+
+	if b.Json {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "\t")
+		return e.Encode(advice)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 8, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "node\tseqno\tsafe_to_bootstrap\tgrastate.dat\tlast stop\trecovered\tlast primary\t")
+	for _, e := range advice.Evidence {
+		fmt.Fprintln(w, strings.Join(evidenceRow(e), "\t")+"\t")
+	}
+	w.Flush()
+
+	fmt.Println()
+	if advice.Node == "" {
+		fmt.Println(utils.Paint(utils.RedText, "no node could be recommended"))
+	} else {
+		var color utils.Color = utils.GreenText
+		switch advice.Confidence {
+		case analysis.ConfidenceMedium:
+			color = utils.YellowText
+		case analysis.ConfidenceLow:
+			color = utils.RedText
+		}
+		fmt.Println(utils.Paint(utils.GreenText, "bootstrap from: "+advice.Node) + " (confidence: " + utils.Paint(color, string(advice.Confidence)) + ")")
+		for _, reason := range advice.Reasons {
+			fmt.Println("\t- " + reason)
+		}
+	}
+	for _, warning := range advice.Warnings {
+		fmt.Println(utils.Paint(utils.YellowText, "warning: ") + warning)
+	}
+	return nil
+}
+
+// evidenceRow shows "unknown" for what the logs did not tell, it should never be read as a no
+func evidenceRow(e analysis.BootstrapEvidence) []string {
+	seqno, lastPrimary := "unknown", ""
+	if e.Position.Known() {
+		seqno = strconv.FormatInt(e.Position.Seqno, 10)
+	}
+	if !e.LastPrimary.IsZero() {
+		lastPrimary = e.LastPrimary.Format(reportDateLayout) + " (n=" + strconv.Itoa(e.LastPrimaryMembers) + ")"
+	}
+	return []string{e.Position.Node, seqno, tristate(e.SafeToBootstrap, "yes", "no"), tristate(e.NoGrastate, "missing", "found"), e.LastStop, tristate(e.Recovered, "yes", "no"), lastPrimary}
+}
+
+func tristate(b *bool, yes, no string) string {
+	switch {
+	case b == nil:
+		return "unknown"
+	case *b:
+		return yes
+	default:
+		return no
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/analysis"
+)
+
+func TestEvidenceRow(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name     string
+		evidence analysis.BootstrapEvidence
+		expected []string
+	}{
+		{
+			name:     "no evidence",
+			evidence: analysis.BootstrapEvidence{Position: analysis.Position{Node: "node1", Seqno: -1}},
+			expected: []string{"node1", "unknown", "unknown", "unknown", "", "unknown", ""},
+		},
+		{
+			name:     "evidence found",
+			evidence: analysis.BootstrapEvidence{Position: analysis.Position{Node: "node1", Seqno: -1}, SafeToBootstrap: &no, NoGrastate: &no, LastStop: "crash", Recovered: &yes},
+			expected: []string{"node1", "unknown", "no", "found", "crash", "yes", ""},
+		},
+	}
+
+	for _, test := range tests {
+		if row := evidenceRow(test.evidence); !reflect.DeepEqual(row, test.expected) {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, row)
+		}
+	}
+}
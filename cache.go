@@ -0,0 +1,207 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+// extractCache stores the local timeline extracted from each source, so that unchanged files do not have to be searched again
+// Events are serialized with their contexts and displayers, the same way snapshots are
+type extractCache struct {
+	dir string
+}
+
+// cachedTimeline is what is written on disk for a single source
+type cachedTimeline struct {
+	Key      string              `json:"key"`
+	Timeline types.LocalTimeline `json:"timeline"`
+}
+
+// the content hash only reads the start and the end of files, logs are only appended to
+const cacheHashedSize = 1024 * 1024
+
+var extracts *extractCache
+
+// newExtractCache returns nil when the cache can't be used
+func newExtractCache(disabled bool, dir string) *extractCache {
+	if disabled {
+		return nil
+	}
+	if dir == "" {
+		base, err := os.UserCacheDir() // $XDG_CACHE_HOME, or ~/.cache
+		if err != nil {
+			logger.Debug().Err(err).Msg("no cache directory, results will not be cached")
+			return nil
+		}
+		dir = filepath.Join(base, "galera-log-explainer")
+	}
+	return &extractCache{dir: dir}
+}
+
+// key identifies what was extracted: the file, its content, the rule set and every flag changing events, and by which version
+// --include-regexes, --exclude-regexes and --pxc-operator are part of compiledRegex
+// ok is false for sources that can't be identified, such as stdin
+func (c *extractCache) key(source logSource, regexes types.RegexMap, compiledRegex string) (string, bool) {
+	if c == nil || source.path == "stdin" || source.file == "" {
+		return "", false
+	}
+	file := source.file
+	if source.archive != "" {
+		file = source.archive
+	}
+	fingerprint, err := fileFingerprint(file)
+	if err != nil {
+		return "", false
+	}
+
+	// custom rules can change handlers without changing their regexes
+	rules := []string{}
+	for _, rule := range CLI.Rules {
+		ruleFingerprint, err := fileFingerprint(rule)
+		if err != nil {
+			return "", false
+		}
+		rules = append(rules, ruleFingerprint)
+	}
+
+	keys := make([]string, 0, len(regexes))
+	for key := range regexes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filters := ""
+	if CLI.Since != nil {
+		filters += "since=" + CLI.Since.Format(time.RFC3339Nano)
+	}
+	if CLI.Until != nil {
+		filters += "until=" + CLI.Until.Format(time.RFC3339Nano)
+	}
+	if CLI.NativeGrep {
+		filters += "native"
+	}
+	filters += fmt.Sprintf("flavor=%s tz=%q display-tz=%s", CLI.Flavor, CLI.Tz, CLI.DisplayTz)
+
+	h := sha256.New()
+	for _, part := range []string{toolVersion(), source.path, fingerprint, strings.Join(rules, ","), strings.Join(keys, ","), compiledRegex, filters} {
+		// the length avoids ambiguities between parts
+		fmt.Fprintf(h, "%d:%s\n", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+func (c *extractCache) load(key string) (types.LocalTimeline, bool) {
+	content, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	cached := cachedTimeline{}
+	if err := json.Unmarshal(content, &cached); err != nil || cached.Key != key {
+		logger.Debug().Str("key", key).Msg("invalid cache entry, ignoring it")
+		return nil, false
+	}
+	shareContexts(cached.Timeline)
+	return cached.Timeline, true
+}
+
+// shareContexts links contexts together again, as handlers left them
+// maps and conflicts are shared by every context of a file while it is read, they were saved once per event
+func shareContexts(lt types.LocalTimeline) {
+	if len(lt) == 0 {
+		return
+	}
+	last := lt[len(lt)-1].Ctx
+	conflicts := map[string]*types.Conflict{}
+	for _, c := range last.Conflicts {
+		conflicts[c.Seqno] = c
+	}
+	for i := range lt {
+		ctx := &lt[i].Ctx
+		ctx.HashToIP, ctx.HashToNodeName, ctx.IPToHostname, ctx.IPToMethod, ctx.IPToNodeName = last.HashToIP, last.HashToNodeName, last.IPToHostname, last.IPToMethod, last.IPToNodeName
+		for j, c := range ctx.Conflicts {
+			if shared, ok := conflicts[c.Seqno]; ok {
+				ctx.Conflicts[j] = shared
+			}
+		}
+	}
+}
+
+// save writes to a temporary file first, concurrent runs would otherwise read half written entries
+func (c *extractCache) save(key string, lt types.LocalTimeline) error {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return errors.Wrap(err, "failed to create cache directory")
+	}
+	content, err := json.Marshal(cachedTimeline{Key: key, Timeline: lt})
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize cache entry")
+	}
+	f, err := os.CreateTemp(c.dir, key+".tmp-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create cache entry")
+	}
+	_, err = f.Write(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return errors.Wrap(err, "failed to write cache entry")
+	}
+	return errors.Wrap(os.Rename(f.Name(), c.path(key)), "failed to write cache entry")
+}
+
+func (c *extractCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// fileFingerprint changes as soon as the file is modified, without reading it entirely
+func fileFingerprint(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(f, 0, cacheHashedSize)); err != nil {
+		return "", err
+	}
+	if st.Size() > cacheHashedSize {
+		if _, err := io.Copy(h, io.NewSectionReader(f, st.Size()-cacheHashedSize, cacheHashedSize)); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%s:%d:%d:%x", file, st.Size(), st.ModTime().UnixNano(), h.Sum(nil)), nil
+}
+
+// toolVersion changes whenever handlers could behave differently
+// builds without ldflags use the binary itself
+func toolVersion() string {
+	if version != "" || commit != "" {
+		return version + ":" + commit
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "dev"
+	}
+	st, err := os.Stat(exe)
+	if err != nil {
+		return "dev"
+	}
+	return fmt.Sprintf("dev:%d:%d", st.Size(), st.ModTime().UnixNano())
+}
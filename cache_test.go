@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+)
+
+func TestExtractCache(t *testing.T) {
+	testCLI(t)
+	paths := []string{"testdata/db1.example.com/172.17.0.2.log", "testdata/db2.example.com/172.17.0.3.log"}
+
+	searched, err := timelineFromPaths(paths, regex.AllRegexes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	extracts = newExtractCache(false, dir)
+	if _, err := timelineFromPaths(paths, regex.AllRegexes()); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(paths) {
+		t.Fatalf("expected an entry per file, got %d", len(entries))
+	}
+
+	cached, err := timelineFromPaths(paths, regex.AllRegexes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := json.Marshal(searched)
+	out, _ := json.Marshal(cached)
+	if string(out) != string(expected) {
+		t.Errorf("cached timeline differs:\nexpected %s\ngot      %s", expected, out)
+	}
+
+	// contexts of a file share their maps, as when they were extracted
+	lt := cached["pxc-a"]
+	if len(lt) < 2 {
+		t.Fatalf("unexpected timeline: %v", cached)
+	}
+	lt[len(lt)-1].Ctx.HashToIP["test"] = "test"
+	if lt[0].Ctx.HashToIP["test"] != "test" {
+		t.Errorf("contexts are not shared anymore")
+	}
+}
@@ -3,16 +3,21 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/ylacancellera/galera-log-explainer/display"
 	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
 	"github.com/ylacancellera/galera-log-explainer/utils"
 	"gopkg.in/yaml.v2"
 )
 
 type conflicts struct {
-	Paths []string `arg:"" name:"paths" help:"paths of the log to use"`
-	Yaml  bool     `xor:"format"`
-	Json  bool     `xor:"format"`
+	Paths    []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Yaml     bool     `xor:"format"`
+	Json     bool     `xor:"format"`
+	Markdown bool     `xor:"format"`
 }
 
 func (c *conflicts) Help() string {
@@ -46,6 +51,8 @@ func (c *conflicts) Run() error {
 				return err
 			}
 			out = string(tmp)
+		} else if c.Markdown {
+			out = conflictsMarkdown(ctx.Conflicts)
 		} else {
 
 			for _, conflict := range ctx.Conflicts {
@@ -70,3 +77,34 @@ func (c *conflicts) Run() error {
 
 	return nil
 }
+
+// conflictsMarkdown is the same summary as the default output, meant to be pasted in tickets
+func conflictsMarkdown(conflicts types.Conflicts) string {
+	out := ""
+	for _, conflict := range conflicts {
+		out += "\n### seqno " + conflict.Seqno + "\n"
+		winner := "_unresolved_"
+		if conflict.Winner != "" {
+			winner = "`" + conflict.Winner + "`"
+		}
+		out += "\n* **winner**: " + winner
+		out += "\n* **initiated by**: " + strings.Join(conflict.InitiatedBy, ", ")
+		out += "\n\n| node | vote | won | error |\n| --- | --- | --- | --- |"
+
+		nodes := make([]string, 0, len(conflict.VotePerNode))
+		for node := range conflict.VotePerNode {
+			nodes = append(nodes, node)
+		}
+		sort.Strings(nodes)
+		for _, node := range nodes {
+			vote := conflict.VotePerNode[node]
+			won := "no"
+			if vote.MD5 == conflict.Winner {
+				won = "yes"
+			}
+			out += fmt.Sprintf("\n| %s | `%s` | %s | %s |", display.MarkdownEscape(node), vote.MD5, won, display.MarkdownEscape(vote.Error))
+		}
+		out += "\n"
+	}
+	return out
+}
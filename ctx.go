@@ -18,11 +18,13 @@ func (c *ctx) Help() string {
 
 func (c *ctx) Run() error {
 
-	if len(c.Paths) != 1 {
-		return errors.New("Can only use 1 path at a time for ctx subcommand")
+	// archives are checked once expanded, they can hold several logs
+	sources := expandPaths(c.Paths)
+	if len(sources) != 1 {
+		return errors.Errorf("Can only use 1 log at a time for ctx subcommand, found %d", len(sources))
 	}
 
-	timeline, err := timelineFromPaths(c.Paths, regex.AllRegexes())
+	timeline, err := timelineFromSources(sources, regex.AllRegexes())
 	if err != nil {
 		return err
 	}
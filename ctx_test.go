@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archives holding several logs are rejected the same way several paths are
+func TestCtxSingleLog(t *testing.T) {
+	testCLI(t)
+	savedTemporaryFiles := temporaryFiles
+	t.Cleanup(func() {
+		cleanupTemporaryFiles()
+		temporaryFiles = savedTemporaryFiles
+	})
+	temporaryFiles = nil
+
+	log, err := os.ReadFile(sourcesTestLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	single := filepath.Join(dir, "single.tar")
+	if err := os.WriteFile(single, tarred(t, map[string][]byte{"var/log/mysqld.log": log, "var/lib/mysql/ibdata1": {0, 1}}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	several := filepath.Join(dir, "several.tar")
+	if err := os.WriteFile(several, tarred(t, map[string][]byte{"var/log/mysqld.log": log, "var/log/mysqld.log.1.gz": gzipped(t, log)}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+		err   bool
+	}{
+		{name: "log", paths: []string{sourcesTestLog}},
+		{name: "archive with a single log", paths: []string{single}},
+		{name: "archive with several logs", paths: []string{several}, err: true},
+		{name: "several logs", paths: []string{sourcesTestLog, "testdata/db2.example.com/172.17.0.3.log"}, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the context itself is printed to stdout
+			stdout := os.Stdout
+			os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+			err := (&ctx{Paths: test.paths}).Run()
+			os.Stdout.Close()
+			os.Stdout = stdout
+
+			if test.err && err == nil {
+				t.Errorf("expected an error")
+			}
+			if !test.err && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/pkg/errors"
+)
+
+// dateMapper reads --since and --until
+// they accept RFC3339 dates, or durations relative to now: -2h, -3d, +30m
+func dateMapper(ctx *kong.DecodeContext, target reflect.Value) error {
+	var value string
+	if err := ctx.Scan.PopValueInto("date", &value); err != nil {
+		return err
+	}
+	t, err := parseDate(value, time.Now())
+	if err != nil {
+		return err
+	}
+	if target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+	target.Set(reflect.ValueOf(t))
+	return nil
+}
+
+func parseDate(value string, now time.Time) (time.Time, error) {
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		d, err := parseDuration(value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid date %s, expected RFC3339 (2023-01-23T03:53:40Z) or a relative duration (-2h)", value)
+	}
+	return t, nil
+}
+
+// parseDuration is time.ParseDuration, with days on top as logs are often checked a few days after the fact
+func parseDuration(value string) (time.Duration, error) {
+	trimmed := value
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		trimmed = value[1:]
+	}
+	if strings.HasSuffix(trimmed, "d") {
+		n, err := strconv.ParseUint(strings.TrimSuffix(trimmed, "d"), 10, 32)
+		if err != nil {
+			return 0, errors.Errorf("invalid duration %s", value)
+		}
+		d := time.Duration(n) * 24 * time.Hour
+		if strings.HasPrefix(value, "-") {
+			d = -d
+		}
+		return d, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid duration %s", value)
+	}
+	return d, nil
+}
+
+// parseAround reads --around, a date and a margin: 2023-01-23T03:53:40Z±15m
+// "~" can be used instead of "±" for keyboards without it
+func parseAround(value string, now time.Time) (since, until time.Time, err error) {
+	i, sepLen := strings.LastIndex(value, "±"), len("±")
+	if i < 0 {
+		i, sepLen = strings.LastIndex(value, "~"), len("~")
+	}
+	if i < 0 {
+		return since, until, errors.Errorf("invalid --around %s, expected a date and a margin: 2023-01-23T03:53:40Z±15m", value)
+	}
+	center, err := parseDate(value[:i], now)
+	if err != nil {
+		return since, until, err
+	}
+	margin, err := parseDuration(value[i+sepLen:])
+	if err != nil {
+		return since, until, err
+	}
+	if margin < 0 {
+		margin = -margin
+	}
+	return center.Add(-margin), center.Add(margin), nil
+}
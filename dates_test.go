@@ -0,0 +1,139 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kong"
+)
+
+var testNow = time.Date(2023, time.January, 23, 3, 53, 40, 0, time.UTC)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Time
+		err      bool
+	}{
+		{value: "-3d", expected: testNow.Add(-72 * time.Hour)},
+		{value: "+3d", expected: testNow.Add(72 * time.Hour)},
+		{value: "-2h", expected: testNow.Add(-2 * time.Hour)},
+		{value: "-1h30m", expected: testNow.Add(-90 * time.Minute)},
+		{value: "+30m", expected: testNow.Add(30 * time.Minute)},
+		{value: "2023-01-20T10:00:00Z", expected: time.Date(2023, time.January, 20, 10, 0, 0, 0, time.UTC)},
+		{value: "2023-01-20T10:00:00+02:00", expected: time.Date(2023, time.January, 20, 8, 0, 0, 0, time.UTC)},
+		{value: "2023-01-20T10:00:00.123Z", expected: time.Date(2023, time.January, 20, 10, 0, 0, 123000000, time.UTC)},
+		{value: "", err: true},
+		{value: "yesterday", err: true},
+		{value: "2023-01-20", err: true},
+		{value: "2023-01-20 10:00:00", err: true},
+		{value: "-d", err: true},
+		{value: "-1.5d", err: true},
+		{value: "--3d", err: true},
+		{value: "-+3d", err: true},
+		{value: "-3w", err: true},
+		{value: "-", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := parseDate(test.value, testNow)
+			if test.err {
+				if err == nil {
+					t.Errorf("expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(test.expected) {
+				t.Errorf("expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseAround(t *testing.T) {
+	tests := []struct {
+		value        string
+		since, until time.Time
+		err          bool
+	}{
+		{value: "2023-01-20T10:00:00Z±15m", since: time.Date(2023, time.January, 20, 9, 45, 0, 0, time.UTC), until: time.Date(2023, time.January, 20, 10, 15, 0, 0, time.UTC)},
+		{value: "2023-01-20T10:00:00Z~15m", since: time.Date(2023, time.January, 20, 9, 45, 0, 0, time.UTC), until: time.Date(2023, time.January, 20, 10, 15, 0, 0, time.UTC)},
+		{value: "2023-01-20T10:00:00+02:00±1h", since: time.Date(2023, time.January, 20, 7, 0, 0, 0, time.UTC), until: time.Date(2023, time.January, 20, 9, 0, 0, 0, time.UTC)},
+		{value: "2023-01-20T10:00:00Z±1d", since: time.Date(2023, time.January, 19, 10, 0, 0, 0, time.UTC), until: time.Date(2023, time.January, 21, 10, 0, 0, 0, time.UTC)},
+		{value: "-2h~30m", since: testNow.Add(-150 * time.Minute), until: testNow.Add(-90 * time.Minute)},
+		{value: "-3d±-1h", since: testNow.Add(-73 * time.Hour), until: testNow.Add(-71 * time.Hour)},
+		{value: "2023-01-20T10:00:00Z", err: true},
+		{value: "2023-01-20T10:00:00Z±", err: true},
+		{value: "2023-01-20T10:00:00Z±soon", err: true},
+		{value: "yesterday~1h", err: true},
+		{value: "±15m", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			since, until, err := parseAround(test.value, testNow)
+			if test.err {
+				if err == nil {
+					t.Errorf("expected an error, got %s - %s", since, until)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !since.Equal(test.since) || !until.Equal(test.until) {
+				t.Errorf("expected %s - %s, got %s - %s", test.since, test.until, since, until)
+			}
+		})
+	}
+}
+
+func TestDateMapper(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected time.Time
+		// relative dates are checked against the time of parsing
+		relative time.Duration
+		err      bool
+	}{
+		{args: []string{"--since", "2023-01-20T10:00:00Z"}, expected: time.Date(2023, time.January, 20, 10, 0, 0, 0, time.UTC)},
+		{args: []string{"--since=-3d"}, relative: -72 * time.Hour},
+		{args: []string{"--since", "yesterday"}, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.args[len(test.args)-1], func(t *testing.T) {
+			var cli struct {
+				Since *time.Time `type:"date"`
+			}
+			parser, err := kong.New(&cli, kong.NamedMapper("date", kong.MapperFunc(dateMapper)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			now := time.Now()
+			_, err = parser.Parse(test.args)
+			if test.err {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cli.Since == nil {
+				t.Fatal("--since was not set")
+			}
+			if test.relative != 0 {
+				test.expected = now.Add(test.relative)
+			}
+			if d := cli.Since.Sub(test.expected); d < 0 || d > time.Minute {
+				t.Errorf("expected %s, got %s", test.expected, cli.Since)
+			}
+		})
+	}
+}
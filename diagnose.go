@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/analysis"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+type diagnose struct {
+	Paths []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Json  bool     `help:"Print findings as json"`
+}
+
+func (d *diagnose) Help() string {
+	return `Look for known issues in logs, and give advices for each of them
+
+Usage:
+	galera-log-explainer diagnose *.log
+	galera-log-explainer diagnose --json *.log
+`
+}
+
+var severityColors = map[analysis.Severity]utils.Color{
+	analysis.SeverityInfo:     utils.BlueText,
+	analysis.SeverityWarning:  utils.YellowText,
+	analysis.SeverityCritical: utils.RedText,
+}
+
+func (d *diagnose) Run() error {
+
+	timeline, err := timelineFromPaths(d.Paths, regex.AllRegexes())
+	if err != nil {
+		return errors.Wrap(err, "Could not diagnose")
+	}
+
+	findings := analysis.Diagnose(timeline, analysis.Rules)
+
+	if d.Json {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "\t")
+		return e.Encode(findings)
+	}
+
+	if len(findings) == 0 {
+		fmt.Println("No known issue found")
+		return nil
+	}
+	for _, f := range findings {
+		// findings about the whole cluster are not tied to a node
+		node := ""
+		if f.Node != "" {
+			node = f.Node + ": "
+		}
+		fmt.Println(utils.Paint(severityColors[f.Severity], "["+strings.ToUpper(string(f.Severity))+"]") + " " + f.Date.Format(reportDateLayout) + " " + node + f.Title)
+		if f.Details != "" {
+			fmt.Println("\t" + f.Details)
+		}
+		fmt.Println("\t" + utils.Paint(utils.BlueText, "advice: ") + f.Advice)
+		if f.Log != "" {
+			fmt.Println("\t" + utils.Paint(utils.BlueText, "log: ") + f.Log)
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// tabwriter can only align columns once every rows are known
+// when following logs, rows are printed right away so columns get a fixed width instead
+const (
+	followDateWidth   = 27
+	followColumnWidth = 30
+	followPadding     = 3
+)
+
+// FollowCLI prints events as soon as they are found, with the same columns as TimelineCLI
+type FollowCLI struct {
+	w              io.Writer
+	keys           []string
+	verbosity      types.Verbosity
+	widths         []int
+	currentContext map[string]types.LogCtx
+}
+
+func NewFollowCLI(w io.Writer, keys []string, verbosity types.Verbosity) *FollowCLI {
+	widths := []int{followDateWidth}
+	for _, key := range keys {
+		width := followColumnWidth
+		if len(key) > width {
+			width = len(key)
+		}
+		widths = append(widths, width)
+	}
+	return &FollowCLI{w: w, keys: keys, verbosity: verbosity, widths: widths, currentContext: map[string]types.LogCtx{}}
+}
+
+// Header prints the same headers as TimelineCLI
+func (f *FollowCLI) Header(ctxs map[string]types.LogCtx) {
+	for node, ctx := range ctxs {
+		f.currentContext[node] = ctx
+	}
+	f.printRow(headerNodes(f.keys))
+	f.printRow(headerFilePath(f.keys, ctxs))
+	f.printRow(headerIP(f.keys, ctxs))
+	f.printRow(headerName(f.keys, ctxs))
+	f.printRow(headerVersion(f.keys, ctxs))
+	if header := headerSeqno(f.keys, ctxs); header != "" {
+		f.printRow(header)
+	}
+	f.printRow(separator(f.keys))
+}
+
+// Event prints a single event on its own row
+// latestContext should be the most updated context known for this node
+func (f *FollowCLI) Event(node string, loginfo types.LogInfo, latestContext types.LogCtx) {
+	f.currentContext[node] = loginfo.Ctx
+
+	msg := loginfo.Msg(latestContext)
+	if f.verbosity <= loginfo.Verbosity || msg == "" {
+		return
+	}
+
+	args := []string{""}
+	if loginfo.Date != nil {
+		args = []string{loginfo.Date.DisplayTime}
+	}
+	for _, key := range f.keys {
+		if key == node {
+			args = append(args, msg)
+			continue
+		}
+		args = append(args, utils.PaintForState("| ", f.currentContext[key].State()))
+	}
+	f.printRow(strings.Join(args, "\t"))
+}
+
+// printRow takes a tabulated row, the same ones given to tabwriter, and pads each cell
+func (f *FollowCLI) printRow(row string) {
+	cells := strings.Split(strings.TrimSuffix(row, "\t"), "\t")
+	out := ""
+	for i, cell := range cells {
+		out += cell
+		if i < len(f.widths) {
+			padding := f.widths[i] - visibleLen(cell)
+			if padding < 0 {
+				padding = 0
+			}
+			out += strings.Repeat(" ", padding+followPadding)
+		}
+	}
+	fmt.Fprintln(f.w, strings.TrimRight(out, " "))
+}
+
+// visibleLen ignores color special characters, the same way the tabwriter fork does
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(utils.StripColors(s))
+}
@@ -0,0 +1,49 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+func TestFollowCLIEvent(t *testing.T) {
+	utils.SkipColor = true
+
+	tests := []struct {
+		name        string
+		node        string
+		loginfo     types.LogInfo
+		expectedOut string
+	}{
+		{
+			name:        "first column",
+			node:        "node0",
+			loginfo:     types.NewLogInfo(types.NewDate(time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), types.SimpleDisplayer("starting(8.0.30)"), "", &types.LogRegex{Verbosity: types.Info}, "", types.LogCtx{}, ""),
+			expectedOut: "2023-01-02T03:04:05.000000Z   starting(8.0.30)                 |\n",
+		},
+		{
+			name:        "second column",
+			node:        "node1",
+			loginfo:     types.NewLogInfo(types.NewDate(time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), types.SimpleDisplayer("a message longer than the column width"), "", &types.LogRegex{Verbosity: types.Info}, "", types.LogCtx{}, ""),
+			expectedOut: "2023-01-02T03:04:05.000000Z   |                                a message longer than the column width\n",
+		},
+		{
+			name:        "hidden by verbosity",
+			node:        "node1",
+			loginfo:     types.NewLogInfo(types.NewDate(time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), types.SimpleDisplayer("my_idx=0"), "", &types.LogRegex{Verbosity: types.DebugMySQL}, "", types.LogCtx{}, ""),
+			expectedOut: "",
+		},
+	}
+
+	for _, test := range tests {
+		out := &bytes.Buffer{}
+		f := NewFollowCLI(out, []string{"node0", "node1"}, types.Detailed)
+		f.Event(test.node, test.loginfo, types.LogCtx{})
+		if out.String() != test.expectedOut {
+			t.Errorf("%s failed:\nexpected: %q\ngot:      %q", test.name, test.expectedOut, out.String())
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	// regular tabwriter do not work with color, this is a forked versions that ignores color special characters
 	"github.com/Ladicle/tabwriter"
@@ -13,9 +14,23 @@ import (
 	"github.com/ylacancellera/galera-log-explainer/utils"
 )
 
+// Band is a period highlighted across every columns, such as a network partition
+// Nodes are printed in each node column on the row starting the band
+type Band struct {
+	Start, End time.Time
+	Color      utils.Color
+	Title      string
+	Nodes      map[string]string
+
+	started, ended bool
+}
+
 // TimelineCLI print a timeline to the terminal using tabulated format
 // It will print header and footers, and dequeue the timeline chronologically
-func TimelineCLI(timeline types.Timeline, verbosity types.Verbosity) {
+// bands are delimited by rows of their own, and the dates of events inside them are highlighted
+func TimelineCLI(timeline types.Timeline, verbosity types.Verbosity, bands ...Band) {
+
+	bands = append([]Band{}, bands...)
 
 	timeline = removeEmptyColumns(timeline, verbosity)
 
@@ -35,6 +50,12 @@ func TimelineCLI(timeline types.Timeline, verbosity types.Verbosity) {
 	fmt.Fprintln(w, headerIP(keys, latestContext))
 	fmt.Fprintln(w, headerName(keys, latestContext))
 	fmt.Fprintln(w, headerVersion(keys, latestContext))
+	if header := headerSeqno(keys, latestContext); header != "" {
+		fmt.Fprintln(w, header)
+	}
+	if header := headerClockSkew(keys, latestContext); header != "" {
+		fmt.Fprintln(w, header)
+	}
 	fmt.Fprintln(w, separator(keys))
 
 	var (
@@ -50,6 +71,14 @@ func TimelineCLI(timeline types.Timeline, verbosity types.Verbosity) {
 		args = []string{""}
 		if date != nil {
 			args = []string{date.DisplayTime}
+			for i := range bands {
+				for _, row := range bands[i].boundaries(keys, date.Time, currentContext) {
+					fmt.Fprintln(w, row)
+				}
+				if bands[i].started && !bands[i].ended {
+					args[0] = utils.Paint(bands[i].Color, "▌") + args[0]
+				}
+			}
 		}
 
 		displayedValue := 0
@@ -113,11 +142,43 @@ func TimelineCLI(timeline types.Timeline, verbosity types.Verbosity) {
 		fmt.Fprintln(w, headerIP(keys, currentContext))
 		fmt.Fprintln(w, headerName(keys, currentContext))
 		fmt.Fprintln(w, headerVersion(keys, currentContext))
+		if header := headerSeqno(keys, currentContext); header != "" {
+			fmt.Fprintln(w, header)
+		}
+		if header := headerClockSkew(keys, currentContext); header != "" {
+			fmt.Fprintln(w, header)
+		}
 	}
 
 	// TODO: where to print conflicts details ?
 }
 
+// boundaries returns the rows to print when the date enters or leaves the band
+func (b *Band) boundaries(keys []string, date time.Time, ctxs map[string]types.LogCtx) []string {
+	rows := []string{}
+	if !b.started && !date.Before(b.Start) {
+		b.started = true
+		row := utils.Paint(b.Color, "▼ "+b.Title) + "\t"
+		for _, node := range keys {
+			if text, ok := b.Nodes[node]; ok {
+				row += utils.Paint(b.Color, text) + "\t"
+			} else {
+				row += utils.PaintForState("| ", ctxs[node].State()) + "\t"
+			}
+		}
+		rows = append(rows, row)
+	}
+	if b.started && !b.ended && date.After(b.End) {
+		b.ended = true
+		row := utils.Paint(b.Color, "▲ end of "+b.Title) + "\t"
+		for _, node := range keys {
+			row += utils.PaintForState("| ", ctxs[node].State()) + "\t"
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
 func initKeysContext(timeline types.Timeline) ([]string, map[string]types.LogCtx) {
 	currentContext := map[string]types.LogCtx{}
 
@@ -195,6 +256,46 @@ func headerName(keys []string, ctxs map[string]types.LogCtx) string {
 	return header
 }
 
+// headerSeqno is empty when no positions were found
+func headerSeqno(keys []string, ctxs map[string]types.LogCtx) string {
+	header := "last known seqno\t"
+	found := false
+	for _, node := range keys {
+		if ctx, ok := ctxs[node]; ok && ctx.Seqno != "" {
+			header += ctx.Seqno + "\t"
+			found = true
+		} else {
+			header += " \t"
+		}
+	}
+	if !found {
+		return ""
+	}
+	return header
+}
+
+// headerClockSkew is empty when skews were not estimated
+func headerClockSkew(keys []string, ctxs map[string]types.LogCtx) string {
+	header := "clock skew\t"
+	found := false
+	for _, node := range keys {
+		if ctx, ok := ctxs[node]; ok && ctx.ClockSkew != nil {
+			skew := ctx.ClockSkew.Round(time.Millisecond).String()
+			if *ctx.ClockSkew > 0 {
+				skew = "+" + skew
+			}
+			header += skew + "\t"
+			found = true
+		} else {
+			header += " \t"
+		}
+	}
+	if !found {
+		return ""
+	}
+	return header
+}
+
 func removeEmptyColumns(timeline types.Timeline, verbosity types.Verbosity) types.Timeline {
 
 	for key := range timeline {
@@ -247,30 +348,10 @@ const NumberOfPossibleTransition = 4
 // so the whole next functions are here to ensure it takes minimal spaces, while giving context and preserving columns
 func transitionSeparator(keys []string, oldctxs, ctxs map[string]types.LogCtx) string {
 
-	ts := map[string]*transitions{}
+	ts := contextTransitions(keys, oldctxs, ctxs)
 
-	// For each columns to print, we build tests
+	// we resolve tests
 	for _, node := range keys {
-		ctx, ok1 := ctxs[node]
-		oldctx, ok2 := oldctxs[node]
-
-		ts[node] = &transitions{tests: []*transition{}}
-		if ok1 && ok2 {
-			ts[node].tests = append(ts[node].tests, &transition{s1: oldctx.FilePath, s2: ctx.FilePath, changeType: "file path"})
-
-			if len(oldctx.OwnNames) > 0 && len(ctx.OwnNames) > 0 {
-				ts[node].tests = append(ts[node].tests, &transition{s1: oldctx.OwnNames[len(oldctx.OwnNames)-1], s2: ctx.OwnNames[len(ctx.OwnNames)-1], changeType: "node name"})
-			}
-			if len(oldctx.OwnIPs) > 0 && len(ctx.OwnIPs) > 0 {
-				ts[node].tests = append(ts[node].tests, &transition{s1: oldctx.OwnIPs[len(oldctx.OwnIPs)-1], s2: ctx.OwnIPs[len(ctx.OwnIPs)-1], changeType: "node ip"})
-			}
-			if oldctx.Version != "" && ctx.Version != "" {
-				ts[node].tests = append(ts[node].tests, &transition{s1: oldctx.Version, s2: ctx.Version, changeType: "version"})
-			}
-
-		}
-
-		// we resolve tests
 		ts[node].fillEmptyTransition()
 		ts[node].iterate()
 	}
@@ -306,6 +387,34 @@ func transitionSeparator(keys []string, oldctxs, ctxs map[string]types.LogCtx) s
 	return out
 }
 
+// contextTransitions builds the tests for every columns: file path, node name, ip, version
+// they are not resolved yet, so that each display can decide how to print them
+func contextTransitions(keys []string, oldctxs, ctxs map[string]types.LogCtx) map[string]*transitions {
+
+	ts := map[string]*transitions{}
+
+	for _, node := range keys {
+		ctx, ok1 := ctxs[node]
+		oldctx, ok2 := oldctxs[node]
+
+		ts[node] = &transitions{tests: []*transition{}}
+		if ok1 && ok2 {
+			ts[node].tests = append(ts[node].tests, &transition{s1: oldctx.FilePath, s2: ctx.FilePath, changeType: "file path"})
+
+			if len(oldctx.OwnNames) > 0 && len(ctx.OwnNames) > 0 {
+				ts[node].tests = append(ts[node].tests, &transition{s1: oldctx.OwnNames[len(oldctx.OwnNames)-1], s2: ctx.OwnNames[len(ctx.OwnNames)-1], changeType: "node name"})
+			}
+			if len(oldctx.OwnIPs) > 0 && len(ctx.OwnIPs) > 0 {
+				ts[node].tests = append(ts[node].tests, &transition{s1: oldctx.OwnIPs[len(oldctx.OwnIPs)-1], s2: ctx.OwnIPs[len(ctx.OwnIPs)-1], changeType: "node ip"})
+			}
+			if oldctx.Version != "" && ctx.Version != "" {
+				ts[node].tests = append(ts[node].tests, &transition{s1: oldctx.Version, s2: ctx.Version, changeType: "version"})
+			}
+		}
+	}
+	return ts
+}
+
 func (ts *transitions) iterate() {
 
 	for _, test := range ts.tests {
@@ -1,7 +1,9 @@
 package display
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ylacancellera/galera-log-explainer/types"
 	"github.com/ylacancellera/galera-log-explainer/utils"
@@ -184,3 +186,32 @@ func TestTransitionSeparator(t *testing.T) {
 
 	}
 }
+
+func TestBandBoundaries(t *testing.T) {
+	utils.SkipColor = true
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	keys := []string{"node0", "node1"}
+	ctxs := map[string]types.LogCtx{"node0": {}, "node1": {}}
+	b := Band{Start: start, End: start.Add(time.Minute), Title: "network partition", Nodes: map[string]string{"node0": "NON-PRIMARY(n=1)"}}
+
+	if rows := b.boundaries(keys, start.Add(-time.Second), ctxs); len(rows) != 0 {
+		t.Errorf("nothing expected before the band, got %#v", rows)
+	}
+	expected := []string{"▼ network partition\tNON-PRIMARY(n=1)\t| \t"}
+	if rows := b.boundaries(keys, start, ctxs); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected %#v, got %#v", expected, rows)
+	}
+	if rows := b.boundaries(keys, start.Add(time.Second), ctxs); len(rows) != 0 {
+		t.Errorf("nothing expected inside the band, got %#v", rows)
+	}
+	expected = []string{"▲ end of network partition\t| \t| \t"}
+	if rows := b.boundaries(keys, start.Add(2*time.Minute), ctxs); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected %#v, got %#v", expected, rows)
+	}
+
+	// a band between two events starts and ends at once
+	b = Band{Start: start, End: start.Add(time.Minute), Title: "split-brain"}
+	if rows := b.boundaries(keys, start.Add(2*time.Minute), ctxs); len(rows) != 2 {
+		t.Errorf("expected the band to start and end, got %#v", rows)
+	}
+}
@@ -0,0 +1,190 @@
+package display
+
+import (
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// htmlReport is everything the template needs, so that the template itself stays dumb
+type htmlReport struct {
+	Keys    []string
+	Headers [][]string
+	Rows    []htmlRow
+}
+
+type htmlRow struct {
+	Date        string
+	Cells       []htmlCell
+	Transitions [][]htmlTransition // one list per column
+}
+
+type htmlCell struct {
+	Color string // from utils.ColorForState
+	Msg   template.HTML
+	Log   string
+}
+
+type htmlTransition struct {
+	From, To, ChangeType string
+}
+
+// TimelineHTML writes the timeline as a single self-contained html file
+// It has the same columns, headers and transitions as TimelineCLI, and each event can be expanded to see its raw log
+func TimelineHTML(w io.Writer, timeline types.Timeline, verbosity types.Verbosity) error {
+
+	timeline = removeEmptyColumns(timeline, verbosity)
+
+	keys, currentContext := initKeysContext(timeline)
+	latestContext := timeline.GetLatestUpdatedContextsByNodes()
+
+	report := htmlReport{Keys: keys}
+	headers := []string{
+		headerFilePath(keys, currentContext),
+		headerIP(keys, latestContext),
+		headerName(keys, latestContext),
+		headerVersion(keys, latestContext),
+	}
+	if header := headerSeqno(keys, latestContext); header != "" {
+		headers = append(headers, header)
+	}
+	if header := headerClockSkew(keys, latestContext); header != "" {
+		headers = append(headers, header)
+	}
+	for _, header := range headers {
+		report.Headers = append(report.Headers, htmlHeader(header, len(keys)))
+	}
+
+	walkTimeline(timeline, verbosity, keys, currentContext, latestContext,
+		func(found [][]*transition) {
+			row := htmlRow{}
+			for _, ts := range found {
+				column := []htmlTransition{}
+				for _, t := range ts {
+					column = append(column, htmlTransition{From: t.s1, To: t.s2, ChangeType: t.changeType})
+				}
+				row.Transitions = append(row.Transitions, column)
+			}
+			report.Rows = append(report.Rows, row)
+		},
+		func(date string, cells []timelineCell) {
+			row := htmlRow{Date: date}
+			for _, cell := range cells {
+				c := htmlCell{Color: utils.ColorForState(cell.state)}
+				if cell.loginfo != nil {
+					c.Msg = ansiToHTML(cell.msg)
+					c.Log = cell.loginfo.Log
+				}
+				row.Cells = append(row.Cells, c)
+			}
+			report.Rows = append(report.Rows, row)
+		})
+
+	return htmlTemplate.Execute(w, report)
+}
+
+// htmlHeader reuses the tabulated headers from TimelineCLI
+// some headers can have missing columns, so they are padded
+func htmlHeader(header string, width int) []string {
+	cells := strings.Split(strings.TrimSuffix(header, "\t"), "\t")
+	for len(cells) < width+1 {
+		cells = append(cells, "")
+	}
+	return cells
+}
+
+var ansiToClass = map[string]string{
+	string(utils.RedText):           "red",
+	string(utils.GreenText):         "green",
+	string(utils.YellowText):        "yellow",
+	string(utils.BlueText):          "blue",
+	string(utils.MagentaText):       "magenta",
+	string(utils.CyanText):          "cyan",
+	string(utils.BrightText):        "bright",
+	string(utils.BrightRedText):     "bright red",
+	string(utils.BrightGreenText):   "bright green",
+	string(utils.BrightYellowText):  "bright yellow",
+	string(utils.BrightBlueText):    "bright blue",
+	string(utils.BrightMagentaText): "bright magenta",
+	string(utils.BrightCyanText):    "bright cyan",
+}
+
+// ansiToHTML converts the terminal colors used in messages into spans
+// text is escaped, every other color codes are dropped
+func ansiToHTML(s string) template.HTML {
+	var b strings.Builder
+	open := 0
+	last := 0
+	for _, loc := range utils.ColorCodes(s) {
+		b.WriteString(template.HTMLEscapeString(s[last:loc[0]]))
+		last = loc[1]
+
+		if class, ok := ansiToClass[s[loc[0]:loc[1]]]; ok {
+			b.WriteString(`<span class="` + class + `">`)
+			open++
+			continue
+		}
+		for ; open > 0; open-- {
+			b.WriteString("</span>")
+		}
+	}
+	b.WriteString(template.HTMLEscapeString(s[last:]))
+	for ; open > 0; open-- {
+		b.WriteString("</span>")
+	}
+	return template.HTML(b.String())
+}
+
+var htmlTemplate = template.Must(template.New("timeline").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>galera-log-explainer</title>
+<style>
+body { font-family: monospace; font-size: 13px; margin: 0; }
+table { border-collapse: collapse; }
+thead { position: sticky; top: 0; background: #fff; box-shadow: 0 1px 0 #999; }
+th, td { padding: 2px 10px; text-align: left; vertical-align: top; white-space: nowrap; }
+thead th:first-child, tbody td:first-child { color: #666; }
+td.cell { border-left: 4px solid #ddd; }
+td.cell.green { border-left-color: #4caf50; }
+td.cell.yellow { border-left-color: #f0b400; }
+td.cell.red { border-left-color: #e53935; }
+tr.transition td { background: #eef3fb; }
+.marker { color: #1a4fa0; }
+.marker small { color: #4a78c0; }
+details summary { cursor: pointer; }
+details pre { margin: 2px 0; padding: 4px; background: #f4f4f4; white-space: pre-wrap; max-width: 60em; }
+span.red { color: #c62828; }
+span.green { color: #2e7d32; }
+span.yellow { color: #b08800; }
+span.blue { color: #1565c0; }
+span.magenta { color: #8e24aa; }
+span.cyan { color: #00838f; }
+span.bright { font-weight: bold; }
+</style>
+</head>
+<body>
+<table>
+<thead>
+<tr><th>identifier</th>{{range .Keys}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Headers}}
+<tr>{{range .}}<th>{{.}}</th>{{end}}</tr>
+{{- end}}
+</thead>
+<tbody>
+{{- range .Rows}}
+{{- if .Transitions}}
+<tr class="transition"><td></td>{{range .Transitions}}<td>{{range .}}<div class="marker">{{.From}} &rarr; {{.To}} <small>({{.ChangeType}})</small></div>{{end}}</td>{{end}}</tr>
+{{- else}}
+<tr><td>{{.Date}}</td>{{range .Cells}}<td class="cell {{.Color}}">{{if .Msg}}<details><summary>{{.Msg}}</summary><pre>{{.Log}}</pre></details>{{end}}</td>{{end}}</tr>
+{{- end}}
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
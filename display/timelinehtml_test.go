@@ -0,0 +1,42 @@
+package display
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+func TestAnsiToHTML(t *testing.T) {
+	utils.SkipColor = false
+
+	tests := []struct {
+		input    string
+		expected template.HTML
+	}{
+		{
+			input:    "OPEN -> PRIMARY",
+			expected: "OPEN -&gt; PRIMARY",
+		},
+		{
+			input:    utils.Paint(utils.GreenText, "PRIMARY") + "(n=2)",
+			expected: `<span class="green">PRIMARY</span>(n=2)`,
+		},
+		{
+			input:    utils.Paint(utils.BrightRedText, "<crash>"),
+			expected: `<span class="bright red">&lt;crash&gt;</span>`,
+		},
+		{
+			// not closed
+			input:    string(utils.YellowText) + "DONOR",
+			expected: `<span class="yellow">DONOR</span>`,
+		},
+	}
+
+	for _, test := range tests {
+		out := ansiToHTML(test.input)
+		if out != test.expected {
+			t.Errorf("input: %q, expected: %q, got: %q", test.input, test.expected, out)
+		}
+	}
+}
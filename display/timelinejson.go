@@ -0,0 +1,120 @@
+package display
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// JSONEvent is the exported form of a LogInfo
+// Message is rendered the same way as TimelineCLI would, without colors
+type JSONEvent struct {
+	Node            string          `json:"node"`
+	Date            *time.Time      `json:"date,omitempty"`
+	Log             string          `json:"log"`
+	RegexUsed       string          `json:"regexUsed"`
+	RegexType       types.RegexType `json:"regexType"`
+	Verbosity       types.Verbosity `json:"verbosity"`
+	RepetitionCount int             `json:"repetitionCount"`
+	Message         string          `json:"message"`
+	Ctx             JSONCtx         `json:"ctx"`
+}
+
+// JSONCtx is a snapshot of the LogCtx fields relevant for each event
+type JSONCtx struct {
+	FilePath    string   `json:"filePath"`
+	FileType    string   `json:"fileType"`
+	State       string   `json:"state"`
+	OwnNames    []string `json:"ownNames"`
+	OwnIPs      []string `json:"ownIPs"`
+	OwnHashes   []string `json:"ownHashes"`
+	Version     string   `json:"version"`
+	SST         JSONSST  `json:"sst"`
+	MemberCount int      `json:"memberCount"`
+	Desynced    bool     `json:"desynced"`
+	ClusterUUID string   `json:"clusterUUID,omitempty"`
+	Seqno       string   `json:"seqno,omitempty"`
+}
+
+// JSONSST is types.SST with json keys, types.SST is kept as-is for snapshots
+type JSONSST struct {
+	Method           string `json:"method"`
+	Type             string `json:"type"`
+	ResyncingNode    string `json:"resyncingNode"`
+	ResyncedFromNode string `json:"resyncedFromNode"`
+}
+
+// TimelineJSON prints the timeline as a single json array of events, in chronological order
+func TimelineJSON(w io.Writer, timeline types.Timeline, verbosity types.Verbosity) error {
+	e := json.NewEncoder(w)
+	e.SetEscapeHTML(false)
+	e.SetIndent("", "\t")
+	return e.Encode(jsonEvents(timeline, verbosity))
+}
+
+// TimelineNDJSON prints one json event per line, in chronological order
+func TimelineNDJSON(w io.Writer, timeline types.Timeline, verbosity types.Verbosity) error {
+	e := json.NewEncoder(w)
+	e.SetEscapeHTML(false)
+	for _, event := range jsonEvents(timeline, verbosity) {
+		if err := e.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonEvents dequeues the timeline chronologically, the same way TimelineCLI does
+// only events that would be visible in TimelineCLI with the same verbosity are kept
+func jsonEvents(timeline types.Timeline, verbosity types.Verbosity) []JSONEvent {
+	events := []JSONEvent{}
+	latestContext := timeline.GetLatestUpdatedContextsByNodes()
+
+	for nextNodes := timeline.IterateNode(); len(nextNodes) != 0; nextNodes = timeline.IterateNode() {
+		for _, node := range nextNodes {
+			loginfo := timeline[node][0]
+			timeline.Dequeue(node)
+
+			msg := loginfo.Msg(latestContext[node])
+			if verbosity <= loginfo.Verbosity || msg == "" {
+				continue
+			}
+
+			event := JSONEvent{
+				Node:            node,
+				Log:             loginfo.Log,
+				RegexUsed:       loginfo.RegexUsed,
+				RegexType:       loginfo.RegexType,
+				Verbosity:       loginfo.Verbosity,
+				RepetitionCount: loginfo.RepetitionCount,
+				Message:         utils.StripColors(msg),
+				Ctx:             newJSONCtx(loginfo.Ctx),
+			}
+			if loginfo.Date != nil {
+				event.Date = &loginfo.Date.Time
+			}
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func newJSONCtx(ctx types.LogCtx) JSONCtx {
+	return JSONCtx{
+		FilePath:    ctx.FilePath,
+		FileType:    ctx.FileType,
+		State:       ctx.State(),
+		OwnNames:    ctx.OwnNames,
+		OwnIPs:      ctx.OwnIPs,
+		OwnHashes:   ctx.OwnHashes,
+		Version:     ctx.Version,
+		SST:         JSONSST(ctx.SST),
+		MemberCount: ctx.MemberCount,
+		Desynced:    ctx.Desynced,
+		ClusterUUID: ctx.ClusterUUID,
+		Seqno:       ctx.Seqno,
+	}
+}
@@ -0,0 +1,37 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+func TestTimelineNDJSON(t *testing.T) {
+	utils.SkipColor = false
+
+	ctx := types.NewLogCtx()
+	ctx.OwnNames = []string{"node0"}
+	ctx.SetState("SYNCED")
+
+	timeline := types.Timeline{
+		"node0": types.LocalTimeline{
+			types.NewLogInfo(types.NewDate(time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), types.SimpleDisplayer(utils.Paint(utils.GreenText, "JOINED -> SYNCED")), "raw log", &types.LogRegex{Type: types.StatesRegexType}, "RegexShift", ctx, ""),
+			types.NewLogInfo(types.NewDate(time.Date(2023, time.January, 2, 3, 4, 6, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), types.SimpleDisplayer("my_idx=0"), "raw log 2", &types.LogRegex{Verbosity: types.DebugMySQL}, "RegexMyIDXFromComponent", ctx, ""),
+		},
+	}
+
+	expected := `{"node":"node0","date":"2023-01-02T03:04:05Z","log":"raw log","regexUsed":"RegexShift","regexType":"states","verbosity":0,"repetitionCount":0,"message":"JOINED -> SYNCED","ctx":{"filePath":"","fileType":"","state":"SYNCED","ownNames":["node0"],"ownIPs":null,"ownHashes":null,"version":"","sst":{"method":"","type":"","resyncingNode":"","resyncedFromNode":""},"memberCount":0,"desynced":false}}
+`
+
+	out := &bytes.Buffer{}
+	err := TimelineNDJSON(out, timeline, types.Detailed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,105 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// TimelineMarkdown prints the timeline as a github flavored markdown table
+// It has the same columns, headers and transitions as TimelineCLI, without colors
+// Since colors are lost, a node's state is written whenever it changes, in the first cell where the node has nothing to display
+func TimelineMarkdown(w io.Writer, timeline types.Timeline, verbosity types.Verbosity) error {
+
+	timeline = removeEmptyColumns(timeline, verbosity)
+
+	keys, currentContext := initKeysContext(timeline)
+	latestContext := timeline.GetLatestUpdatedContextsByNodes()
+
+	rows := []string{
+		markdownRow(strings.Split(strings.TrimSuffix(headerNodes(keys), "\t"), "\t"), len(keys)),
+		"|" + strings.Repeat(" --- |", len(keys)+1),
+	}
+	// markdown tables can only have a single header row, the others are emphasized instead
+	headers := []string{
+		headerFilePath(keys, currentContext),
+		headerIP(keys, latestContext),
+		headerName(keys, latestContext),
+		headerVersion(keys, latestContext),
+	}
+	if header := headerSeqno(keys, latestContext); header != "" {
+		headers = append(headers, header)
+	}
+	if header := headerClockSkew(keys, latestContext); header != "" {
+		headers = append(headers, header)
+	}
+	for _, header := range headers {
+		cells := []string{}
+		for _, cell := range strings.Split(strings.TrimSuffix(header, "\t"), "\t") {
+			if cell = strings.TrimSpace(cell); cell != "" {
+				cell = "**" + cell + "**"
+			}
+			cells = append(cells, cell)
+		}
+		rows = append(rows, markdownRow(cells, len(keys)))
+	}
+
+	lastDisplayedState := map[string]string{}
+	for _, node := range keys {
+		lastDisplayedState[node] = currentContext[node].State()
+	}
+
+	walkTimeline(timeline, verbosity, keys, currentContext, latestContext,
+		func(found [][]*transition) {
+			cells := []string{""}
+			for _, ts := range found {
+				changes := []string{}
+				for _, t := range ts {
+					changes = append(changes, fmt.Sprintf("`%s` → `%s` (%s)", t.s1, t.s2, t.changeType))
+				}
+				cells = append(cells, strings.Join(changes, "<br>"))
+			}
+			rows = append(rows, markdownRow(cells, len(keys)))
+		},
+		func(date string, cells []timelineCell) {
+			out := []string{date}
+			for i, cell := range cells {
+				node := keys[i]
+				switch {
+				case cell.loginfo != nil:
+					out = append(out, utils.StripColors(cell.msg))
+					lastDisplayedState[node] = cell.state
+				case cell.state != lastDisplayedState[node]:
+					out = append(out, "_"+cell.state+"_")
+					lastDisplayedState[node] = cell.state
+				default:
+					out = append(out, "")
+				}
+			}
+			rows = append(rows, markdownRow(out, len(keys)))
+		})
+
+	_, err := fmt.Fprintln(w, strings.Join(rows, "\n"))
+	return err
+}
+
+// markdownRow escapes and pads cells, so that every rows have the date column and one column per node
+func markdownRow(cells []string, width int) string {
+	for len(cells) < width+1 {
+		cells = append(cells, "")
+	}
+	for i := range cells {
+		cells[i] = MarkdownEscape(cells[i])
+	}
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", "<br>")
+
+// MarkdownEscape makes text safe to put in a markdown table cell
+func MarkdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,62 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+func TestTimelineMarkdown(t *testing.T) {
+	utils.SkipColor = false
+
+	// NewLogCtx is not used, so that columns are not considered empty
+	ctx1 := types.LogCtx{FilePath: "mysqld.log.1"}
+	ctx1.SetState("SYNCED")
+	ctx2 := ctx1
+	ctx2.FilePath = "mysqld.log"
+	ctx2.SetState("DONOR")
+
+	layout := "2006-01-02T15:04:05.000000Z"
+	timeline := types.Timeline{
+		"node0": types.LocalTimeline{
+			types.NewLogInfo(types.NewDate(time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC), layout), types.SimpleDisplayer(utils.Paint(utils.GreenText, "SYNCED")+" | ok"), "raw log", &types.LogRegex{}, "", ctx1, ""),
+			types.NewLogInfo(types.NewDate(time.Date(2023, time.January, 2, 3, 4, 7, 0, time.UTC), layout), types.SimpleDisplayer("hidden"), "raw log 3", &types.LogRegex{Verbosity: types.DebugMySQL}, "", ctx2, ""),
+		},
+		"node1": types.LocalTimeline{
+			types.NewLogInfo(types.NewDate(time.Date(2023, time.January, 2, 3, 4, 6, 0, time.UTC), layout), types.SimpleDisplayer("event"), "raw log 2", &types.LogRegex{}, "", types.LogCtx{}, ""),
+			types.NewLogInfo(types.NewDate(time.Date(2023, time.January, 2, 3, 4, 8, 0, time.UTC), layout), types.SimpleDisplayer("event 2"), "raw log 4", &types.LogRegex{}, "", types.LogCtx{}, ""),
+		},
+	}
+
+	expected := `| identifier | node0 | node1 |
+| --- | --- | --- |
+| **current path** | **mysqld.log.1** |  |
+| **last known ip** |  |  |
+| **last known name** |  |  |
+| **mysql version** |  |  |
+| 2023-01-02T03:04:05.000000Z | SYNCED \| ok |  |
+| 2023-01-02T03:04:06.000000Z |  | event |
+|  | ` + "`mysqld.log.1` → `mysqld.log` (file path)" + ` |  |
+| 2023-01-02T03:04:08.000000Z | _DONOR_ | event 2 |
+`
+
+	out := &bytes.Buffer{}
+	err := TimelineMarkdown(out, timeline, types.Detailed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	in := "Duplicate entry 'a|b' for key 'PRIMARY'\nError_code: 1062"
+	expected := `Duplicate entry 'a\|b' for key 'PRIMARY'<br>Error_code: 1062`
+	if out := MarkdownEscape(in); out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
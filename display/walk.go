@@ -0,0 +1,91 @@
+package display
+
+import (
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// timelineCell is what a column has to display on a row
+// loginfo is nil when the node has nothing to display, only its state is then useful
+type timelineCell struct {
+	loginfo *types.LogInfo
+	msg     string
+	state   string
+}
+
+// walkTimeline dequeues the timeline chronologically, the same way TimelineCLI does, for displays that are not tabulated
+// onTransition gets, for each column, the context transitions that happened (file path, name, ip, version)
+// onRow is only called when at least one column has something to display
+// currentContext is updated while walking, so that footers can be built from it
+func walkTimeline(timeline types.Timeline, verbosity types.Verbosity, keys []string, currentContext, latestContext map[string]types.LogCtx,
+	onTransition func([][]*transition), onRow func(date string, cells []timelineCell)) {
+
+	lastContext := map[string]types.LogCtx{}
+
+	for nextNodes := timeline.IterateNode(); len(nextNodes) != 0; nextNodes = timeline.IterateNode() {
+
+		date := ""
+		if d := timeline[nextNodes[0]][0].Date; d != nil {
+			date = d.DisplayTime
+		}
+
+		cells := make([]timelineCell, 0, len(keys))
+		displayedValue := 0
+		for _, node := range keys {
+
+			if !utils.SliceContains(nextNodes, node) {
+				cells = append(cells, timelineCell{state: currentContext[node].State()})
+				continue
+			}
+			loginfo := timeline[node][0]
+			lastContext[node] = currentContext[node]
+			currentContext[node] = loginfo.Ctx
+
+			timeline.Dequeue(node)
+
+			cell := timelineCell{state: loginfo.Ctx.State()}
+			msg := loginfo.Msg(latestContext[node])
+			if verbosity > loginfo.Verbosity && msg != "" {
+				cell.loginfo = &loginfo
+				cell.msg = msg
+				displayedValue++
+			}
+			cells = append(cells, cell)
+		}
+
+		if found := transitionsFound(keys, lastContext, currentContext); found != nil {
+			// same as TimelineCLI, to avoid duplicating transitions
+			lastContext = map[string]types.LogCtx{}
+			for k, v := range currentContext {
+				lastContext[k] = v
+			}
+			onTransition(found)
+		}
+
+		if displayedValue == 0 {
+			continue
+		}
+		onRow(date, cells)
+	}
+}
+
+// transitionsFound returns, for each column, the transitions that actually happened
+// nil when nothing changed
+func transitionsFound(keys []string, oldctxs, ctxs map[string]types.LogCtx) [][]*transition {
+	ts := contextTransitions(keys, oldctxs, ctxs)
+
+	found := false
+	out := make([][]*transition, len(keys))
+	for i, node := range keys {
+		for _, test := range ts[node].tests {
+			if test.s1 != test.s2 {
+				out[i] = append(out[i], test)
+				found = true
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/display"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+const followInterval = 500 * time.Millisecond
+
+// follower reads a file as it grows, handling truncations and rotations like "tail -F"
+type follower struct {
+	path    string
+	file    *os.File
+	reader  *bufio.Reader
+	partial string
+}
+
+type followedLine struct {
+	idx  int
+	line string
+}
+
+func newFollower(path string) (*follower, error) {
+	sources, err := sourcesFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) != 1 || !sources[0].regular || sources[0].archive != "" {
+		return nil, errors.Errorf("%s: only regular files can be followed, not compressed files or archives", path)
+	}
+	f := &follower{path: path}
+	return f, f.open()
+}
+
+func (f *follower) open() error {
+	if f.file != nil {
+		f.file.Close()
+	}
+	file, err := os.Open(f.path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", f.path)
+	}
+	f.file = file
+	f.reader = bufio.NewReaderSize(file, 64*1024)
+	f.partial = ""
+	return nil
+}
+
+// readLines returns every complete line written since the last call
+// an incomplete last line is kept until its end is written
+func (f *follower) readLines() ([]string, error) {
+	lines := []string{}
+	for {
+		s, err := f.reader.ReadString('\n')
+		if err == io.EOF {
+			f.partial += s
+			return lines, nil
+		}
+		if err != nil {
+			return lines, errors.Wrapf(err, "failed to read %s", f.path)
+		}
+		lines = append(lines, strings.TrimSuffix(f.partial+s, "\n"))
+		f.partial = ""
+	}
+}
+
+// poll reads new lines, and reopens the file when it was rotated or truncated
+func (f *follower) poll() ([]string, error) {
+	lines, err := f.readLines()
+	if err != nil {
+		return lines, err
+	}
+
+	current, err := f.file.Stat()
+	if err != nil {
+		return lines, errors.Wrapf(err, "failed to stat %s", f.path)
+	}
+	latest, err := os.Stat(f.path)
+	if err != nil {
+		// the file can be missing for a short time while being rotated
+		return lines, nil
+	}
+
+	offset, err := f.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return lines, errors.Wrapf(err, "failed to get position in %s", f.path)
+	}
+	offset -= int64(f.reader.Buffered())
+
+	switch {
+	case !os.SameFile(current, latest):
+		logger.Debug().Str("path", f.path).Msg("file rotated, reopening")
+	case latest.Size() < offset:
+		logger.Debug().Str("path", f.path).Msg("file truncated, reading from the start")
+	default:
+		return lines, nil
+	}
+
+	if err := f.open(); err != nil {
+		return lines, err
+	}
+	newLines, err := f.readLines()
+	return append(lines, newLines...), err
+}
+
+// followPaths is the live equivalent of timelineFromPaths
+// Everything already written is read to build each node's context, then new lines are handled as they come
+// and printed right away. It never returns, unless files can't be read or --until is reached in every file
+func followPaths(paths []string, regexes types.RegexMap, verbosity types.Verbosity) error {
+
+	regexes, err := prepareRegexes(regexes)
+	if err != nil {
+		return err
+	}
+	matcher := newNativeMatcher(regexes, CLI.PxcOperator)
+
+	followers := make([]*follower, len(paths))
+	builders := make([]*timelineBuilder, len(paths))
+	timeline := types.Timeline{}
+
+	// files are not followed anymore once --until is reached
+	stopped := make([]chan struct{}, len(paths))
+	done := make([]bool, len(paths))
+	running := len(paths)
+	stop := func(i int) {
+		close(stopped[i])
+		done[i] = true
+		running--
+	}
+
+	for i, path := range paths {
+		f, err := newFollower(path)
+		if err != nil {
+			return err
+		}
+		followers[i] = f
+		builders[i] = newTimelineBuilder(path, regexes)
+		stopped[i] = make(chan struct{})
+
+		lines, err := f.readLines()
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if !matcher.match(line) {
+				continue
+			}
+			if _, until := builders[i].handle(line); until {
+				stop(i)
+				break
+			}
+		}
+
+		if len(builders[i].lt) == 0 {
+			continue
+		}
+		// copy, so that the history can be dequeued while the builder keeps its own timeline
+		lt := append(types.LocalTimeline{}, builders[i].lt...)
+		if CLI.MergeByDirectory {
+			timeline.MergeByDirectory(path, lt)
+		} else {
+			timeline.MergeByIdentifier(lt)
+		}
+	}
+
+	// columns are decided once, using what was identified from the existing content
+	columns := make([]string, len(paths))
+	keys := []string{}
+	for i, path := range paths {
+		columns[i] = followColumn(path, builders[i])
+		if !utils.SliceContains(keys, columns[i]) {
+			keys = append(keys, columns[i])
+		}
+	}
+	sort.Strings(keys)
+
+	printer := display.NewFollowCLI(os.Stdout, keys, verbosity)
+	latestContext := timeline.GetLatestUpdatedContextsByNodes()
+	printer.Header(latestContext)
+
+	// history
+	for nextNodes := timeline.IterateNode(); len(nextNodes) != 0; nextNodes = timeline.IterateNode() {
+		for _, node := range nextNodes {
+			printer.Event(node, timeline[node][0], latestContext[node])
+			timeline.Dequeue(node)
+		}
+	}
+
+	newLines := make(chan followedLine)
+	for i, f := range followers {
+		go func(i int, f *follower) {
+			ticker := time.NewTicker(followInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+				case <-stopped[i]:
+					return
+				}
+				lines, err := f.poll()
+				if err != nil {
+					logger.Warn().Str("path", f.path).Err(err).Msg("failed to follow")
+				}
+				for _, line := range lines {
+					select {
+					case newLines <- followedLine{idx: i, line: line}:
+					case <-stopped[i]:
+						return
+					}
+				}
+			}
+		}(i, f)
+	}
+
+	for running > 0 {
+		l := <-newLines
+		if done[l.idx] || !matcher.match(l.line) {
+			continue
+		}
+		added, until := builders[l.idx].handle(l.line)
+		if until {
+			stop(l.idx)
+			continue
+		}
+		for _, li := range added {
+			printer.Event(columns[l.idx], li, latestFollowedContext(li.Ctx, builders))
+		}
+	}
+	return nil
+}
+
+func followColumn(path string, b *timelineBuilder) string {
+	if CLI.MergeByDirectory {
+		return filepath.Base(filepath.Dir(path))
+	}
+	return types.Identifier(b.ctx)
+}
+
+// latestFollowedContext is the equivalent of GetLatestUpdatedContextsByNodes
+// translation maps from every followed files are merged, so that messages use the most readable names
+func latestFollowedContext(ctx types.LogCtx, builders []*timelineBuilder) types.LogCtx {
+	ctxs := make([]types.LogCtx, 0, len(builders))
+	for _, b := range builders {
+		ctxs = append(ctxs, b.ctx)
+	}
+	ctx.MergeMapsWith(ctxs)
+	return ctx
+}
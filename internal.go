@@ -4,13 +4,15 @@ import (
 	"bufio"
 	"os/exec"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/ylacancellera/galera-log-explainer/regex"
 	"github.com/ylacancellera/galera-log-explainer/types"
-	"github.com/ylacancellera/galera-log-explainer/utils"
 )
 
 var logger = log.With().Str("component", "extractor").Logger()
@@ -28,28 +30,32 @@ func init() {
 // timelineFromPaths takes every path, search them using a list of regexes
 // and organize them in a timeline that will be ready to aggregate or read
 func timelineFromPaths(paths []string, regexes types.RegexMap) (types.Timeline, error) {
+	return timelineFromSources(expandPaths(paths), regexes)
+}
+
+// timelineFromSources is timelineFromPaths, for paths already expanded
+func timelineFromSources(sources []logSource, regexes types.RegexMap) (types.Timeline, error) {
 	timeline := make(types.Timeline)
-	found := false
 
+	regexes, err := prepareRegexes(regexes)
+	if err != nil {
+		return nil, err
+	}
 	compiledRegex := prepareGrepArgument(regexes)
 
-	for _, path := range paths {
-		stdout := make(chan string)
+	localTimelines := extractSources(sources, regexes, compiledRegex)
 
-		go func() {
-			err := execGrepAndIterate(path, compiledRegex, stdout)
-			if err != nil {
-				logger.Error().Str("path", path).Err(err).Msg("execGrepAndIterate returned error")
-			}
-		}()
+	// merging is done sequentially, in the order paths were given
+	// so that the result does not depend on which file finished first
+	for i, source := range sources {
+		path := source.path
+		localTimeline := localTimelines[i]
 
-		// it will iterate on stdout pipe results
-		localTimeline, err := iterateOnGrepResults(path, regexes, stdout)
-		if err != nil {
-			logger.Warn().Err(err).Msg("Failed to iterate on results")
+		// files where nothing was found can't be identified, nor displayed
+		// when no file had anything, the timeline is simply empty
+		if len(localTimeline) == 0 {
+			continue
 		}
-		found = true
-		logger.Debug().Str("path", path).Msg("Finished searching")
 
 		// Why it should not just identify using the file path:
 		// so that we are able to merge files that belong to the same nodes
@@ -62,15 +68,130 @@ func timelineFromPaths(paths []string, regexes types.RegexMap) (types.Timeline,
 			timeline.MergeByIdentifier(localTimeline)
 		}
 	}
-	if !found {
-		return nil, errors.New("Could not find data")
-	}
 	return timeline, nil
 }
 
+// extractSources searches every source concurrently, using at most CLI.Jobs workers
+// Local timelines are returned in the same order as the sources
+func extractSources(sources []logSource, regexes types.RegexMap, compiledRegex string) []types.LocalTimeline {
+	localTimelines := make([]types.LocalTimeline, len(sources))
+
+	jobs := CLI.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	toExtract := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range toExtract {
+				localTimelines[i] = extractSource(sources[i], regexes, compiledRegex)
+			}
+		}()
+	}
+	for i := range sources {
+		toExtract <- i
+	}
+	close(toExtract)
+	wg.Wait()
+
+	return localTimelines
+}
+
+// extractSource searches a single source and builds its own local timeline
+// it does not depend on any other source, so it is safe to call concurrently
+func extractSource(source logSource, regexes types.RegexMap, compiledRegex string) types.LocalTimeline {
+	key, cacheable := extracts.key(source, regexes, compiledRegex)
+	if cacheable {
+		if localTimeline, ok := extracts.load(key); ok {
+			logger.Debug().Str("path", source.path).Msg("Found in cache")
+			return localTimeline
+		}
+	}
+	source = seekSource(source)
+
+	stdout := make(chan string)
+	stop := make(chan struct{})
+	errc := make(chan error, 1)
+
+	go func() {
+		var err error
+		if CLI.NativeGrep {
+			err = nativeGrepAndIterate(source, regexes, stdout, stop)
+		} else {
+			err = execGrepAndIterate(source, compiledRegex, stdout, stop)
+		}
+		if err != nil {
+			logger.Error().Str("path", source.path).Err(err).Msg("execGrepAndIterate returned error")
+		}
+		errc <- err
+	}()
+
+	// it will iterate on stdout pipe results
+	localTimeline, err := iterateOnGrepResults(source.path, regexes, stdout, stop)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to iterate on results")
+	}
+	logger.Debug().Str("path", source.path).Msg("Finished searching")
+
+	// a failed search would be cached as if there were nothing to find
+	if grepErr := <-errc; cacheable && err == nil && (grepErr == nil || grepErr == errNothingFound) {
+		if err := extracts.save(key, localTimeline); err != nil {
+			logger.Debug().Str("path", source.path).Err(err).Msg("failed to cache results")
+		}
+	}
+	return localTimeline
+}
+
+// prepareRegexes returns the regexes to search for, with custom rules and pxc operator regexes, once filtered
+// the given map is not modified, it usually is one shared by every command, such as regex.IdentsMap
+func prepareRegexes(regexes types.RegexMap) (types.RegexMap, error) {
+	regexes = make(types.RegexMap, len(regexes)).Merge(regexes)
+	if err := addCustomRules(regexes); err != nil {
+		return nil, err
+	}
+	if CLI.PxcOperator {
+		regexes.Merge(regex.PXCOperatorMap)
+	}
+	if err := filterRegexes(regexes); err != nil {
+		return nil, err
+	}
+	return regexes, nil
+}
+
+// addCustomRules merges regexes defined in --rules files
+// they can replace builtin regexes using the same key
+func addCustomRules(regexes types.RegexMap) error {
+	if len(CLI.Rules) == 0 {
+		return nil
+	}
+	custom, err := regex.LoadCustomRules(CLI.Rules)
+	if err != nil {
+		return err
+	}
+	for key := range custom {
+		if _, ok := regexes[key]; ok {
+			logger.Warn().Str("key", key).Msg("builtin regex replaced by a custom rule")
+		}
+	}
+	regexes.Merge(custom)
+	return nil
+}
+
 func prepareGrepArgument(regexes types.RegexMap) string {
 
-	regexToSendSlice := regexes.Compile()
+	operatorRegexes, otherRegexes := types.RegexMap{}, types.RegexMap{}
+	for key, r := range regexes {
+		if _, ok := regex.PXCOperatorMap[key]; ok {
+			operatorRegexes[key] = r
+		} else {
+			otherRegexes[key] = r
+		}
+	}
+	regexToSendSlice := otherRegexes.Compile()
 
 	grepRegex := "^"
 	if CLI.PxcOperator {
@@ -78,11 +199,14 @@ func prepareGrepArgument(regexes types.RegexMap) string {
 		// I'm not adding pxcoperator map the same way others are used, because they do not have the same formats and same place
 		// it needs to be put on the front so that it's not 'merged' with the '{"log":"' json prefix
 		// this is to keep things as close as '^' as possible to keep doing prefix searches
-		grepRegex += "((" + strings.Join(regex.PXCOperatorMap.Compile(), "|") + ")|^{\"log\":\""
-		regexes.Merge(regex.PXCOperatorMap)
+		grepRegex += "("
+		if len(operatorRegexes) > 0 {
+			grepRegex += "(" + strings.Join(operatorRegexes.Compile(), "|") + ")|"
+		}
+		grepRegex += "^{\"log\":\""
 	}
 	if CLI.Since != nil {
-		grepRegex += "(" + regex.BetweenDateRegex(CLI.Since, CLI.PxcOperator) + "|" + regex.NoDatesRegex(CLI.PxcOperator) + ")"
+		grepRegex += "(" + regex.BetweenDateRegex(CLI.Since, CLI.PxcOperator, len(tz.rules) > 0) + "|" + regex.NoDatesRegex(CLI.PxcOperator) + ")"
 	}
 	grepRegex += ".*"
 	grepRegex += "(" + strings.Join(regexToSendSlice, "|") + ")"
@@ -93,7 +217,11 @@ func prepareGrepArgument(regexes types.RegexMap) string {
 	return grepRegex
 }
 
-func execGrepAndIterate(path, compiledRegex string, stdout chan<- string) error {
+// errNothingFound is not an actual failure, the file simply has nothing of interest
+var errNothingFound = errors.New("Found nothing")
+
+// execGrepAndIterate sends every line grep found, until stop is closed
+func execGrepAndIterate(source logSource, compiledRegex string, stdout chan<- string, stop <-chan struct{}) error {
 
 	defer close(stdout)
 
@@ -113,29 +241,48 @@ func execGrepAndIterate(path, compiledRegex string, stdout chan<- string) error
 		It also helps to be transparent and not provide an obscure tool that work as a blackbox
 	*/
 	if runtime.GOOS == "darwin" && CLI.GrepCmd == "grep" {
-		logger.Warn().Msg("On Darwin systems, use 'pt-galera-log-explainer --grep-cmd=ggrep' as it requires grep v3")
+		logger.Warn().Msg("On Darwin systems, use 'pt-galera-log-explainer --grep-cmd=ggrep' as it requires grep v3, or use --native-grep")
 	}
 
-	cmd := exec.Command(CLI.GrepCmd, CLI.GrepArgs, compiledRegex, path)
+	var cmd *exec.Cmd
+	if source.regular {
+		cmd = exec.Command(CLI.GrepCmd, CLI.GrepArgs, compiledRegex, source.file)
+	} else {
+		// compressed files and archive members are decompressed here, and given to grep through stdin
+		in, err := source.open()
+		if err != nil {
+			return errors.Wrapf(err, "failed to open %s", source.path)
+		}
+		defer in.Close()
+		cmd = exec.Command(CLI.GrepCmd, CLI.GrepArgs, compiledRegex)
+		cmd.Stdin = in
+	}
 
 	out, _ := cmd.StdoutPipe()
 	defer out.Close()
 
 	err := cmd.Start()
 	if err != nil {
-		return errors.Wrapf(err, "failed to search in %s", path)
+		return errors.Wrapf(err, "failed to search in %s", source.path)
 	}
 
 	// grep treatment
 	s := bufio.NewScanner(out)
 	for s.Scan() {
-		stdout <- s.Text()
+		select {
+		case stdout <- s.Text():
+		case <-stop:
+			// nothing more is needed from this file, no need to let grep read it until the end
+			cmd.Process.Kill()
+			cmd.Wait()
+			return nil
+		}
 	}
 
 	// double-check it stopped correctly
 	if err = cmd.Wait(); err != nil {
 		if exiterr, ok := err.(*exec.ExitError); ok && exiterr.ExitCode() == 1 {
-			return errors.New("Found nothing")
+			return errNothingFound
 		}
 		return errors.Wrap(err, "grep subprocess error")
 	}
@@ -153,50 +300,116 @@ func sanitizeLine(s string) string {
 // iterateOnGrepResults will take line by line each logs that matched regex
 // it will iterate on every regexes in slice, and apply the handler for each
 // it also filters out --since and --until rows
-func iterateOnGrepResults(path string, regexes types.RegexMap, grepStdout <-chan string) (types.LocalTimeline, error) {
+// stop is closed once --until is reached, so that the search ends early
+func iterateOnGrepResults(path string, regexes types.RegexMap, grepStdout <-chan string, stop chan<- struct{}) (types.LocalTimeline, error) {
+
+	b := newTimelineBuilder(path, regexes)
+	for line := range grepStdout {
+		_, until := b.handle(line)
+		if until {
+			close(stop)
+			break
+		}
+	}
+	return b.lt, nil
+}
+
+// timelineBuilder holds what is needed to handle the lines of a single path, one at a time
+type timelineBuilder struct {
+	lt           types.LocalTimeline
+	ctx          types.LogCtx
+	regexes      types.RegexMap
+	keys         []string
+	recentEnough bool
+
+	// flavor is forced with --flavor, else found from the first start banner
+	flavor string
+
+	// loc is the zone of dates without offsets. locFound is set once a --tz pattern matched the path or the node
+	loc      *time.Location
+	locFound bool
+}
 
-	var (
-		lt           types.LocalTimeline
-		recentEnough bool
-		displayer    types.LogDisplayer
-	)
+func newTimelineBuilder(path string, regexes types.RegexMap) *timelineBuilder {
 	ctx := types.NewLogCtx()
 	ctx.FilePath = path
 
-	for line := range grepStdout {
-		line = sanitizeLine(line)
+	// a line can match multiple regexes, and handlers are updating the context
+	// they have to be applied in the same order on every run
+	keys := make([]string, 0, len(regexes))
+	for key := range regexes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-		var date *types.Date
-		t, layout, ok := regex.SearchDateFromLog(line)
-		if ok {
-			date = types.NewDate(t, layout)
-		}
+	flavor := CLI.Flavor
+	if flavor == regex.FlavorAuto {
+		flavor = ""
+	}
+
+	loc, locFound := tz.forPath(path)
+	if !locFound {
+		loc = tz.defaultZone()
+	}
+
+	return &timelineBuilder{ctx: ctx, regexes: regexes, keys: keys, flavor: flavor, loc: loc, locFound: locFound}
+}
+
+// handle applies every regexes matching the line, and returns the events it created
+// stop is true when --until is reached, no more lines should be sent
+func (b *timelineBuilder) handle(line string) (added []types.LogInfo, stop bool) {
+	var displayer types.LogDisplayer
+
+	line = sanitizeLine(line)
+
+	var date *types.Date
+	t, layout, ok := regex.SearchDateFromLog(line)
+	if ok {
+		date = tz.date(t, layout, b.loc)
+	}
 
-		// If it's recentEnough, it means we already validated a log: every next logs necessarily happened later
-		// this is useful because not every logs have a date attached, and some without date are very useful
-		if !recentEnough && CLI.Since != nil && (date == nil || (date != nil && CLI.Since.After(date.Time))) {
+	// If it's recentEnough, it means we already validated a log: every next logs necessarily happened later
+	// this is useful because not every logs have a date attached, and some without date are very useful
+	if !b.recentEnough && CLI.Since != nil && (date == nil || (date != nil && CLI.Since.After(date.Time))) {
+		return nil, false
+	}
+	if CLI.Until != nil && date != nil && CLI.Until.Before(date.Time) {
+		return nil, true
+	}
+	b.recentEnough = true
+
+	filetype := regex.FileType(line, CLI.PxcOperator)
+	b.ctx.FileType = filetype
+
+	if b.flavor == "" {
+		b.flavor = regex.DetectFlavor(line)
+	}
+
+	// We have to find again what regex worked to get this log line
+	// it can match multiple regexes
+	for _, key := range b.keys {
+		regex := b.regexes[key]
+		if regex.Flavor != "" && b.flavor != "" && regex.Flavor != b.flavor {
 			continue
 		}
-		if CLI.Until != nil && date != nil && CLI.Until.Before(date.Time) {
-			return lt, nil
+		if !regex.Regex.MatchString(line) {
+			continue
 		}
-		recentEnough = true
-
-		filetype := regex.FileType(line, CLI.PxcOperator)
-		ctx.FileType = filetype
+		b.ctx, displayer = regex.Handle(b.ctx, line)
+		li := types.NewLogInfo(date, displayer, line, regex, key, b.ctx, filetype)
 
-		// We have to find again what regex worked to get this log line
-		// it can match multiple regexes
-		for key, regex := range regexes {
-			if !regex.Regex.MatchString(line) || utils.SliceContains(CLI.ExcludeRegexes, key) {
-				continue
-			}
-			ctx, displayer = regex.Handle(ctx, line)
-			li := types.NewLogInfo(date, displayer, line, regex, key, ctx, filetype)
+		b.lt = b.lt.Add(li)
+		added = append(added, li)
+	}
 
-			lt = lt.Add(li)
+	// zones given by node names can only be used once the node is identified
+	// what was read before is moved to this zone
+	if !b.locFound {
+		if loc, ok := tz.forNode(b.ctx); ok {
+			tz.relocate(b.lt, b.loc, loc)
+			tz.relocate(added, b.loc, loc)
+			b.loc, b.locFound = loc, true
 		}
-
 	}
-	return lt, nil
+	return added, false
 }
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+)
+
+// testCLI sets what kong would have set by default, and restores the flags once the test is done
+func testCLI(t *testing.T) {
+	saved, savedTz, savedExtracts := CLI, tz, extracts
+	t.Cleanup(func() {
+		CLI, tz, extracts = saved, savedTz, savedExtracts
+	})
+
+	CLI.GrepCmd = "grep"
+	CLI.GrepArgs = "-P"
+	CLI.Flavor = "auto"
+	CLI.Jobs = 1
+	tz = &timezones{}
+	extracts = nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+)
+
+// journald exports are transformed into regular log lines, so that every engines can handle them
+// as if they were read from mysqld error log
+// "journalctl -o export": KEY=value fields, one entry per paragraph. binary fields are KEY\n<64 bits little endian size><data>\n
+// "journalctl -o json": one json object per line
+const (
+	journaldExport = "export"
+	journaldJSON   = "json"
+)
+
+var journaldExportField = regexp.MustCompile(`^[A-Z0-9_]+=`)
+
+// convertJournald detects journald exports and wraps the reader so that it returns log lines instead
+func convertJournald(rc *readCloser) {
+	br := bufio.NewReaderSize(rc.Reader, 64*1024)
+	rc.Reader = br
+
+	format := journaldFormat(br)
+	if format == "" {
+		return
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		var err error
+		switch format {
+		case journaldExport:
+			err = convertJournaldExport(br, pw)
+		case journaldJSON:
+			err = convertJournaldJSON(br, pw)
+		}
+		pw.CloseWithError(err)
+	}()
+
+	rc.Reader = pr
+	rc.closers = append(rc.closers, pr.Close)
+	rc.journald = true
+}
+
+func journaldFormat(br *bufio.Reader) string {
+	peek, _ := br.Peek(4096)
+	firstLine, _, _ := bytes.Cut(peek, []byte("\n"))
+
+	switch {
+	case bytes.HasPrefix(firstLine, []byte("{")) && bytes.Contains(firstLine, []byte(`"__REALTIME_TIMESTAMP"`)):
+		return journaldJSON
+	case journaldExportField.Match(firstLine) && (bytes.HasPrefix(peek, []byte("__REALTIME_TIMESTAMP=")) || bytes.Contains(peek, []byte("\n__REALTIME_TIMESTAMP="))):
+		return journaldExport
+	}
+	return ""
+}
+
+func convertJournaldExport(br *bufio.Reader, w io.Writer) error {
+	var realtime, message string
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		// an empty line ends the entry
+		if line == "" {
+			if message != "" {
+				if _, werr := io.WriteString(w, journaldLines(realtime, message)); werr != nil {
+					return werr
+				}
+			}
+			realtime, message = "", ""
+			if err == io.EOF {
+				return nil
+			}
+			continue
+		}
+
+		key, value, isText := strings.Cut(line, "=")
+		if !isText {
+			value, err = readJournaldBinaryField(br)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read journald field %s", key)
+			}
+		}
+		switch key {
+		case "MESSAGE":
+			message = value
+		case "__REALTIME_TIMESTAMP":
+			realtime = value
+		}
+		if err == io.EOF {
+			if message != "" {
+				_, err = io.WriteString(w, journaldLines(realtime, message))
+			}
+			return err
+		}
+	}
+}
+
+func readJournaldBinaryField(br *bufio.Reader) (string, error) {
+	var size uint64
+	if err := binary.Read(br, binary.LittleEndian, &size); err != nil {
+		return "", err
+	}
+	data := make([]byte, size+1) // +1: trailing newline
+	if _, err := io.ReadFull(br, data); err != nil {
+		return "", err
+	}
+	return string(data[:size]), nil
+}
+
+func convertJournaldJSON(br *bufio.Reader, w io.Writer) error {
+	d := json.NewDecoder(br)
+	for {
+		entry := struct {
+			Message  json.RawMessage `json:"MESSAGE"`
+			Realtime string          `json:"__REALTIME_TIMESTAMP"`
+		}{}
+		err := d.Decode(&entry)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to decode journald json")
+		}
+
+		message := journaldJSONMessage(entry.Message)
+		if message == "" {
+			continue
+		}
+		if _, err := io.WriteString(w, journaldLines(entry.Realtime, message)); err != nil {
+			return err
+		}
+	}
+}
+
+// journald stores non-utf8 messages as an array of bytes
+func journaldJSONMessage(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var b []byte
+	ints := []int{}
+	if err := json.Unmarshal(raw, &ints); err == nil {
+		for _, i := range ints {
+			b = append(b, byte(i))
+		}
+	}
+	return string(b)
+}
+
+// journaldLines adds the journald timestamp when the message does not have one already
+// using the same layout as 5.7+ error logs, so that SearchDateFromLog finds it
+func journaldLines(realtime, message string) string {
+	message = strings.TrimSuffix(message, "\n") + "\n"
+	if _, _, ok := regex.SearchDateFromLog(message); ok {
+		return message
+	}
+	usec, err := strconv.ParseInt(realtime, 10, 64)
+	if err != nil {
+		return message
+	}
+	t := time.Unix(0, usec*int64(time.Microsecond)).UTC()
+	return fmt.Sprintf("%s %s", t.Format(regex.DateLayouts[0]), message)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConvertJournald(t *testing.T) {
+	expected := strings.Join([]string{
+		"2023-01-05T03:23:19.123456Z [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 10)\n",
+		"2023-01-05T03:23:20.654321Z 0 [Note] [MY-000000] [Galera] Shifting DONOR/DESYNCED -> JOINED (TO: 10)\n",
+		"2023-01-05T03:23:22.000000Z [Warning] WSREP: binary=message\n\tsecond line \xff\n",
+		"2023-01-05T03:23:23.000000Z [Note] WSREP: Synchronized with group, ready for connections\n",
+	}, "")
+
+	tests := []struct {
+		name     string
+		file     string
+		journald bool
+		expected string
+	}{
+		{name: "export", file: "testdata/journald/mysqld.export", journald: true, expected: expected},
+		{name: "json", file: "testdata/journald/mysqld.json", journald: true, expected: expected},
+		{name: "error log", file: "testdata/db1.example.com/172.17.0.2.log"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := os.Open(test.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rc := &readCloser{Reader: f, closers: []func() error{f.Close}}
+			defer rc.Close()
+
+			convertJournald(rc)
+			if rc.journald != test.journald {
+				t.Fatalf("expected journald=%t, got %t", test.journald, rc.journald)
+			}
+			out, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !test.journald {
+				raw, err := os.ReadFile(test.file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				test.expected = string(raw)
+			}
+			if string(out) != test.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", test.expected, string(out))
+			}
+		})
+	}
+}
+
+// a truncated binary field is an error rather than a shortened message
+func TestConvertJournaldExportTruncated(t *testing.T) {
+	raw, err := os.ReadFile("testdata/journald/mysqld.export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// cut in the middle of the binary MESSAGE
+	i := strings.Index(string(raw), "binary=message")
+	rc := &readCloser{Reader: strings.NewReader(string(raw[:i]))}
+
+	convertJournald(rc)
+	if _, err := io.ReadAll(rc); err == nil {
+		t.Errorf("expected an error on a truncated binary field")
+	}
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"os"
+	"time"
+
 	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/analysis"
 	"github.com/ylacancellera/galera-log-explainer/display"
 	"github.com/ylacancellera/galera-log-explainer/regex"
 	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
 )
 
 type list struct {
 	// Paths is duplicated because it could not work as variadic with kong cli if I set it as CLI object
-	Paths                  []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Paths                  []string `arg:"" optional:"" name:"paths" help:"paths of the log to use"`
 	SkipStateColoredColumn bool     `help:"avoid having the placeholder colored with mysql state, which is guessed using several regexes that will not be displayed"`
 	All                    bool     `help:"List everything" xor:"states,views,events,sst,applicative"`
 	States                 bool     `help:"List WSREP state changes(SYNCED, DONOR, ...)" xor:"states"`
@@ -17,6 +22,13 @@ type list struct {
 	Events                 bool     `help:"List generic mysql events (start, shutdown, assertion failures)" xor:"events"`
 	SST                    bool     `help:"List Galera synchronization event" xor:"sst"`
 	Applicative            bool     `help:"List applicative events (resyncs, desyncs, conflicts). Events tied to one's usage of Galera" xor:"applicative"`
+	Follow                 bool     `help:"Keep reading the logs as they grow, like 'tail -F', and print new events as they come. With --until, it ends once every file reached it"`
+	Format                 string   `help:"Output format: cli, json, ndjson, html, markdown" enum:"cli,json,ndjson,html,markdown" default:"cli"`
+	FromSnapshot           string   `type:"existingfile" help:"Render a timeline saved with the 'save' command instead of reading logs"`
+
+	ClockSkew    bool          `help:"Estimate how much each node's clock is ahead of the others, using view changes every node logged, and show it in headers"`
+	FixClockSkew bool          `help:"Same as --clock-skew, and also shift each node's dates by its estimated skew so that events are ordered as they happened"`
+	MaxClockSkew time.Duration `default:"1m" help:"Events of the same kind further apart than this are not considered the same when estimating clock skews"`
 }
 
 func (l *list) Help() string {
@@ -24,12 +36,19 @@ func (l *list) Help() string {
 	It will merge logs between themselves
 
 	"identifier" is an internal metadata, this is used to merge logs.
+	With --views or --all, periods when nodes were partitioned from each other are highlighted.
 
 Usage:
 	galera-log-explainer list --all <list of files>
 	galera-log-explainer list --all *.log
 	galera-log-explainer list --sst --views --states <list of files>
 	galera-log-explainer list --events --views *.log
+	galera-log-explainer list --all --follow /var/lib/mysql/mysqld-error.log
+	galera-log-explainer list --all --format=ndjson *.log
+	galera-log-explainer list --all --format=html *.log > report.html
+	galera-log-explainer list --all --format=markdown *.log
+	galera-log-explainer list --all --fix-clock-skew *.log
+	galera-log-explainer list --all --from-snapshot snapshot.json
 	`
 }
 
@@ -41,16 +60,86 @@ func (l *list) Run() error {
 
 	toCheck := l.regexesToUse()
 
-	timeline, err := timelineFromPaths(CLI.List.Paths, toCheck)
+	if l.FromSnapshot != "" {
+		if len(l.Paths) > 0 || l.Follow {
+			return errors.New("--from-snapshot can't be used along with paths or --follow")
+		}
+	} else if len(l.Paths) == 0 {
+		return errors.New("Please give paths of logs to list, or --from-snapshot")
+	}
+
+	if l.Follow {
+		if l.ClockSkew || l.FixClockSkew {
+			return errors.New("clock skews can't be estimated with --follow, every node's logs are needed")
+		}
+		if l.Format != "cli" {
+			return errors.New("--follow only prints the cli format, --format can't be used along with it")
+		}
+		return followPaths(l.Paths, toCheck, CLI.Verbosity)
+	}
+
+	timeline, err := l.timeline(toCheck)
 	if err != nil {
 		return errors.Wrap(err, "Could not list events")
 	}
 
-	display.TimelineCLI(timeline, CLI.Verbosity)
+	if l.ClockSkew || l.FixClockSkew {
+		skews := analysis.EstimateClockSkew(timeline, l.MaxClockSkew)
+		timeline = analysis.ApplyClockSkew(timeline, skews, l.FixClockSkew)
+	}
+
+	switch l.Format {
+	case "json":
+		return display.TimelineJSON(os.Stdout, timeline, CLI.Verbosity)
+	case "ndjson":
+		return display.TimelineNDJSON(os.Stdout, timeline, CLI.Verbosity)
+	case "html":
+		return display.TimelineHTML(os.Stdout, timeline, CLI.Verbosity)
+	case "markdown":
+		return display.TimelineMarkdown(os.Stdout, timeline, CLI.Verbosity)
+	}
+	display.TimelineCLI(timeline, CLI.Verbosity, partitionBands(timeline)...)
 
 	return nil
 }
 
+// partitionBands highlights periods when nodes were in different views, it needs views regexes
+func partitionBands(timeline types.Timeline) []display.Band {
+	bands := []display.Band{}
+	for _, p := range analysis.Partitions(timeline) {
+		band := display.Band{Start: p.Start, End: p.End, Color: utils.YellowText, Title: "network partition", Nodes: map[string]string{}}
+		if p.SplitBrain {
+			band.Color, band.Title = utils.RedText, "split-brain"
+		}
+		for _, c := range p.Components {
+			for _, node := range c.Nodes {
+				band.Nodes[node] = c.Status()
+			}
+		}
+		bands = append(bands, band)
+	}
+	return bands
+}
+
+func (l *list) timeline(toCheck types.RegexMap) (types.Timeline, error) {
+	if l.FromSnapshot == "" {
+		return timelineFromPaths(l.Paths, toCheck)
+	}
+
+	snap, err := loadSnapshot(l.FromSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	if snap.LogsRemoved && (l.ClockSkew || l.FixClockSkew) {
+		return nil, errors.New("clock skews are estimated from raw logs, the snapshot has to be saved with --keep-logs")
+	}
+	toCheck, err = prepareRegexes(toCheck)
+	if err != nil {
+		return nil, err
+	}
+	return selectFromSnapshot(snap.Timeline, toCheck)
+}
+
 func (l *list) regexesToUse() types.RegexMap {
 
 	// IdentRegexes is always needed: we would not be able to identify the node where the file come from
@@ -63,6 +152,10 @@ func (l *list) regexesToUse() types.RegexMap {
 	}
 	if l.Views || l.All {
 		toCheck.Merge(regex.ViewsMap)
+	} else if l.ClockSkew || l.FixClockSkew {
+		// view changes are what clock skews are estimated from
+		regex.SetVerbosity(types.DebugMySQL, regex.ViewsMap)
+		toCheck.Merge(regex.ViewsMap)
 	}
 	if l.SST || l.All {
 		toCheck.Merge(regex.SSTMap)
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/alecthomas/kong"
@@ -32,23 +34,43 @@ func (v *versioncmd) Run() error {
 
 var CLI struct {
 	NoColor          bool
-	Since            *time.Time      `help:"Only list events after this date, format: 2023-01-23T03:53:40Z (RFC3339)"`
-	Until            *time.Time      `help:"Only list events before this date"`
+	Since            *time.Time      `type:"date" help:"Only list events after this date, format: 2023-01-23T03:53:40Z (RFC3339), or relative to now: -2h, -3d"`
+	Until            *time.Time      `type:"date" help:"Only list events before this date. Files stop being read once it is reached"`
+	Around           string          `help:"Only list events around this date, eg: 2023-01-23T03:53:40Z±15m ('~' can be used instead of '±'). Replaces --since and --until"`
 	Verbosity        types.Verbosity `type:"counter" short:"v" default:"1" help:"-v: Detailed (default), -vv: DebugMySQL (add every mysql info the tool used), -vvv: Debug (internal tool debug)"`
 	PxcOperator      bool            `default:"false" help:"Analyze logs from Percona PXC operator. Off by default because it negatively impacts performance for non-k8s setups"`
-	ExcludeRegexes   []string        `help:"Remove regexes from analysis. List regexes using 'galera-log-explainer regex-list'"`
+	ExcludeRegexes   []string        `help:"Remove regexes from analysis. List regexes using 'galera-log-explainer regex-list'. Globs (RegexSST*) and regexes between slashes (/^Regex(SST|IST)/) are accepted"`
+	IncludeRegexes   []string        `help:"Only use these regexes, along with the ones needed to identify nodes. Same format as --exclude-regexes"`
+	IncludeTypes     []string        `help:"Only use regexes of these types, along with the ones needed to identify nodes: events, sst, views, identity, states, applicative, pxc-operator"`
+	ExcludeTypes     []string        `help:"Remove regexes of these types from analysis"`
 	MergeByDirectory bool            `help:"Instead of relying on identification, merge contexts and columns by base directory. Very useful when dealing with many small logs organized per directories."`
+	Jobs             int             `help:"Number of files to analyze concurrently. Defaults to the number of CPUs"`
+	Rules            []string        `type:"existingfile" help:"YAML or JSON files defining additional regexes. See rules.example.yaml"`
+	Tz               []string        `help:"Timezone of logs written without offsets, for every file (Europe/Paris), a node (node1=Europe/Paris) or a path glob (*/eu-*/*.log=Europe/Paris). Dates are then displayed in UTC"`
+	DisplayTz        string          `help:"Timezone used to display dates, with their offsets (eg: UTC, Local, Asia/Tokyo)"`
+	Flavor           string          `enum:"auto,pxc,mariadb" default:"auto" help:"Software that wrote the logs: pxc, mariadb. auto detects it per file from the start banners"`
+	Cache            bool            `help:"Store the events extracted from each file, so that unchanged files are not searched again. Off by default as events hold raw log lines, which are copied to the cache directory"`
+	CacheDir         string          `help:"Where to store the cache, used with --cache. Defaults to $XDG_CACHE_HOME/galera-log-explainer. It can be removed at any time"`
 
-	List      list       `cmd:""`
-	Whois     whois      `cmd:""`
-	Sed       sed        `cmd:""`
-	Ctx       ctx        `cmd:""`
-	RegexList regexList  `cmd:""`
-	Version   versioncmd `cmd:""`
-	Conflicts conflicts  `cmd:""`
+	List             list             `cmd:""`
+	Whois            whois            `cmd:""`
+	Sed              sed              `cmd:""`
+	Ctx              ctx              `cmd:""`
+	RegexList        regexList        `cmd:""`
+	Version          versioncmd       `cmd:""`
+	Conflicts        conflicts        `cmd:""`
+	Summary          summary          `cmd:""`
+	Diagnose         diagnose         `cmd:""`
+	Save             save             `cmd:""`
+	Anonymize        anonymize        `cmd:""`
+	Positions        positions        `cmd:""`
+	BootstrapAdvisor bootstrapAdvisor `cmd:""`
+	Sst              sst              `cmd:""`
+	Views            views            `cmd:""`
 
-	GrepCmd  string `help:"'grep' command path. Could need to be set to 'ggrep' for darwin systems" default:"grep"`
-	GrepArgs string `help:"'grep' arguments. perl regexp (-P) is necessary. -o will break the tool" default:"-P"`
+	GrepCmd    string `help:"'grep' command path. Could need to be set to 'ggrep' for darwin systems" default:"grep"`
+	GrepArgs   string `help:"'grep' arguments. perl regexp (-P) is necessary. -o will break the tool" default:"-P"`
+	NativeGrep bool   `help:"Use the builtin matcher instead of the external 'grep' command. Useful when GNU grep is not available"`
 }
 
 func main() {
@@ -56,6 +78,7 @@ func main() {
 		kong.Name("galera-log-explainer"),
 		kong.Description("An utility to transform Galera logs in a readable version"),
 		kong.UsageOnError(),
+		kong.NamedMapper("date", kong.MapperFunc(dateMapper)),
 	)
 
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
@@ -66,6 +89,30 @@ func main() {
 	}
 
 	utils.SkipColor = CLI.NoColor
-	err := ctx.Run()
+	var err error
+	tz, err = newTimezones(CLI.Tz, CLI.DisplayTz)
+	ctx.FatalIfErrorf(err)
+	extracts = newExtractCache(!CLI.Cache, CLI.CacheDir)
+
+	if CLI.Around != "" {
+		if CLI.Since != nil || CLI.Until != nil {
+			ctx.Fatalf("--around can't be used along with --since or --until")
+		}
+		since, until, err := parseAround(CLI.Around, time.Now())
+		ctx.FatalIfErrorf(err)
+		CLI.Since, CLI.Until = &since, &until
+	}
+
+	// temporary files hold copies of the logs, they are removed even when interrupted
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cleanupTemporaryFiles()
+		os.Exit(1)
+	}()
+
+	err = ctx.Run()
+	cleanupTemporaryFiles()
 	ctx.FatalIfErrorf(err)
 }
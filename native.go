@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// galera can log huge lines (gcomm state dumps, k8s json wrapped logs)
+const maxLineSize = 16 * 1024 * 1024
+
+// nativePattern is a regex to test, along with a literal every matching line has to contain
+// literal can be empty, the regex will then always be tried
+type nativePattern struct {
+	literal string
+	regex   *regexp.Regexp
+
+	// with operator logs, regular regexes only apply to lines starting with the k8s json prefix
+	// operators regexes have their own anchors
+	skipPrefixCheck bool
+}
+
+type nativeMatcher struct {
+	patterns []nativePattern
+	operator bool
+}
+
+func newNativeMatcher(regexes types.RegexMap, operator bool) *nativeMatcher {
+	m := &nativeMatcher{operator: operator}
+	for key, r := range regexes {
+		_, isOperatorRegex := regex.PXCOperatorMap[key]
+		m.patterns = append(m.patterns, nativePattern{
+			literal:         utils.RequiredLiteral(r.Regex),
+			regex:           r.Regex,
+			skipPrefixCheck: isOperatorRegex,
+		})
+	}
+	return m
+}
+
+// match is the equivalent of the single regex sent to grep in prepareGrepArgument
+// dates are not checked here, iterateOnGrepResults already filters them
+func (m *nativeMatcher) match(line string) bool {
+	hasPrefix := strings.HasPrefix(line, `{"log":"`)
+	for _, p := range m.patterns {
+		if m.operator && !p.skipPrefixCheck && !hasPrefix {
+			continue
+		}
+		if p.literal != "" && !strings.Contains(line, p.literal) {
+			continue
+		}
+		if p.regex.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// nativeGrepAndIterate is the builtin alternative to execGrepAndIterate
+// It reads the source directly and sends every line that can be handled by one of the regexes
+// It does not need any external dependency, which is useful for minimal containers and darwin systems
+func nativeGrepAndIterate(source logSource, regexes types.RegexMap, stdout chan<- string, stop <-chan struct{}) error {
+
+	defer close(stdout)
+
+	f, err := source.open()
+	if err != nil {
+		return errors.Wrapf(err, "failed to search in %s", source.path)
+	}
+	defer f.Close()
+
+	matcher := newNativeMatcher(regexes, CLI.PxcOperator)
+	found := false
+
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for s.Scan() {
+		line := s.Text()
+		if !matcher.match(line) {
+			continue
+		}
+		found = true
+		select {
+		case stdout <- line:
+		case <-stop:
+			return nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return errors.Wrapf(err, "failed to read %s", source.path)
+	}
+	if !found {
+		return errNothingFound
+	}
+	return nil
+}
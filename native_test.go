@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+// the builtin matcher, with its literal prefilter, should find exactly what grep finds
+func TestNativeGrep(t *testing.T) {
+	paths := []string{"testdata/db1.example.com/172.17.0.2.log", "testdata/db2.example.com/172.17.0.3.log", "testdata/journald/mysqld.export"}
+
+	tests := []struct {
+		name    string
+		regexes func() types.RegexMap
+	}{
+		{name: "all", regexes: regex.AllRegexes},
+		{name: "sst", regexes: func() types.RegexMap { return types.RegexMap{}.Merge(regex.IdentsMap).Merge(regex.SSTMap) }},
+		{name: "views", regexes: func() types.RegexMap { return types.RegexMap{}.Merge(regex.ViewsMap) }},
+		{name: "states and events", regexes: func() types.RegexMap {
+			return types.RegexMap{}.Merge(regex.StatesMap).Merge(regex.EventsMap)
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testCLI(t)
+			grepped, err := timelineFromPaths(paths, test.regexes())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			CLI.NativeGrep = true
+			matched, err := timelineFromPaths(paths, test.regexes())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(grepped) == 0 {
+				t.Fatal("grep found nothing, the fixtures are wrong")
+			}
+			expected, _ := json.Marshal(grepped)
+			out, _ := json.Marshal(matched)
+			if string(out) != string(expected) {
+				t.Errorf("native timeline differs:\nexpected %s\ngot      %s", expected, out)
+			}
+		})
+	}
+}
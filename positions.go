@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/analysis"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+type positions struct {
+	Paths []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Json  bool     `help:"Print the positions as json"`
+}
+
+func (p *positions) Help() string {
+	return `Compare the latest replication position (cluster UUID and seqno) each node logged
+It tells which node had the most advanced state when the cluster went down, the one to bootstrap from
+
+Usage:
+	galera-log-explainer positions *.log
+	galera-log-explainer positions --until 2023-01-05T04:00:00Z *.log   # for an earlier outage
+
+Seqnos are only logged on some events (state changes, IST, recoveries, inconsistency votes), nodes still running at the end of their logs may have applied more
+When in doubt, compare grastate.dat files or the output of mysqld --wsrep-recover`
+}
+
+func (p *positions) Run() error {
+
+	timeline, err := timelineFromPaths(p.Paths, regex.AllRegexes())
+	if err != nil {
+		return errors.Wrap(err, "Could not find positions")
+	}
+
+	report := analysis.Positions(timeline)
+
+	if p.Json {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "\t")
+		return e.Encode(report)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 8, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "node\tcluster uuid\tseqno\tlogged at\tlast state\tlast seen\t")
+	for _, pos := range report.Positions {
+		seqno, date := "unknown", ""
+		if pos.Known() {
+			seqno = strconv.FormatInt(pos.Seqno, 10)
+			date = pos.Date.Format(reportDateLayout)
+		}
+		fmt.Fprintln(w, strings.Join([]string{pos.Node, pos.ClusterUUID, seqno, date, pos.State, pos.LastSeen.Format(reportDateLayout)}, "\t")+"\t")
+	}
+	w.Flush()
+
+	fmt.Println()
+	if len(report.MostAdvanced) > 0 {
+		fmt.Println(utils.Paint(utils.GreenText, "most advanced: "+strings.Join(report.MostAdvanced, ", ")) + " (seqno " + strconv.FormatInt(report.Positions[0].Seqno, 10) + ")")
+	} else {
+		fmt.Println(utils.Paint(utils.RedText, "no comparable positions found"))
+	}
+	for _, warning := range report.Warnings {
+		fmt.Println(utils.Paint(utils.YellowText, "warning: ") + warning)
+	}
+	return nil
+}
@@ -9,6 +9,63 @@ import (
 
 func init() {
 	setType(types.ApplicativeRegexType, ApplicativeMap)
+
+	// node is either the local node, or a remote one when its name is not known yet
+	syncDisplayer := func(ctx types.LogCtx, params map[string]string) string {
+		if utils.SliceContains(ctx.OwnNames, params["node"]) {
+			return utils.Paint(utils.YellowText, params["msg"])
+		}
+		return params["node"] + utils.Paint(utils.YellowText, " "+params["msg"])
+	}
+	types.RegisterDisplayer("RegexDesync", syncDisplayer)
+	types.RegisterDisplayer("RegexResync", syncDisplayer)
+
+	types.RegisterDisplayer("RegexInconsistencyVoteInit", func(ctx types.LogCtx, params map[string]string) string {
+		if utils.SliceContains(ctx.OwnNames, params["node"]) {
+			return utils.Paint(utils.YellowText, "inconsistency vote started") + "(seqno:" + params["seqno"] + ")"
+		}
+
+		return utils.Paint(utils.YellowText, "inconsistency vote started by "+params["node"]) + "(seqno:" + params["seqno"] + ")"
+	})
+
+	// votes are found in the context, as they are completed by the next events
+	types.RegisterDisplayer("RegexInconsistencyVoteRespond", func(ctx types.LogCtx, params map[string]string) string {
+		c := ctx.Conflicts.ConflictWithSeqno(params["seqno"])
+		if c == nil {
+			return ""
+		}
+		for _, name := range ctx.OwnNames {
+			vote, ok := c.VotePerNode[name]
+			if !ok {
+				continue
+			}
+
+			return voteResponse(vote, *c)
+		}
+
+		return ""
+	})
+
+	types.RegisterDisplayer("RegexInconsistencyWinner", func(ctx types.LogCtx, params map[string]string) string {
+		c := ctx.Conflicts.ConflictWithSeqno(params["seqno"])
+		if c == nil {
+			return ""
+		}
+		out := "consistency vote(seqno:" + c.Seqno + "): "
+		for _, name := range ctx.OwnNames {
+
+			vote, ok := c.VotePerNode[name]
+			if !ok {
+				continue
+			}
+
+			if vote.MD5 == c.Winner {
+				return out + utils.Paint(utils.GreenText, "won")
+			}
+			return out + utils.Paint(utils.RedText, "lost")
+		}
+		return ""
+	})
 }
 
 var ApplicativeMap = types.RegexMap{
@@ -20,12 +77,7 @@ var ApplicativeMap = types.RegexMap{
 			ctx.Desynced = true
 
 			node := submatches[groupNodeName]
-			return ctx, func(ctx types.LogCtx) string {
-				if utils.SliceContains(ctx.OwnNames, node) {
-					return utils.Paint(utils.YellowText, "desyncs itself from group")
-				}
-				return node + utils.Paint(utils.YellowText, " desyncs itself from group")
-			}
+			return ctx, types.NewDisplayer("RegexDesync", map[string]string{"node": node, "msg": "desyncs itself from group"})
 		},
 	},
 
@@ -35,12 +87,7 @@ var ApplicativeMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.Desynced = false
 			node := submatches[groupNodeName]
-			return ctx, func(ctx types.LogCtx) string {
-				if utils.SliceContains(ctx.OwnNames, node) {
-					return utils.Paint(utils.YellowText, "resyncs itself to group")
-				}
-				return node + utils.Paint(utils.YellowText, " resyncs itself to group")
-			}
+			return ctx, types.NewDisplayer("RegexResync", map[string]string{"node": node, "msg": "resyncs itself to group"})
 		},
 	},
 
@@ -54,6 +101,9 @@ var ApplicativeMap = types.RegexMap{
 			errormd5 := submatches[groupErrorMD5]
 			errorstring := submatches["error"]
 
+			// every member logs every votes, the node is at least there
+			ctx.SetPosition(submatches[groupUUID], seqno)
+
 			c := types.Conflict{
 				InitiatedBy: []string{node},
 				Seqno:       seqno,
@@ -62,14 +112,7 @@ var ApplicativeMap = types.RegexMap{
 
 			ctx.Conflicts = ctx.Conflicts.Merge(c)
 
-			return ctx, func(ctx types.LogCtx) string {
-
-				if utils.SliceContains(ctx.OwnNames, node) {
-					return utils.Paint(utils.YellowText, "inconsistency vote started") + "(seqno:" + seqno + ")"
-				}
-
-				return utils.Paint(utils.YellowText, "inconsistency vote started by "+node) + "(seqno:" + seqno + ")"
-			}
+			return ctx, types.NewDisplayer("RegexInconsistencyVoteInit", map[string]string{"node": node, "seqno": seqno})
 		},
 	},
 
@@ -83,32 +126,22 @@ var ApplicativeMap = types.RegexMap{
 			errormd5 := submatches[groupErrorMD5]
 			errorstring := submatches["error"]
 
+			ctx.SetPosition(submatches[groupUUID], seqno)
+
 			latestConflict := ctx.Conflicts.ConflictWithSeqno(seqno)
 			if latestConflict == nil {
 				return ctx, nil
 			}
 			latestConflict.VotePerNode[node] = types.ConflictVote{MD5: errormd5, Error: errorstring}
 
-			return ctx, func(ctx types.LogCtx) string {
-
-				for _, name := range ctx.OwnNames {
-					vote, ok := latestConflict.VotePerNode[name]
-					if !ok {
-						continue
-					}
-
-					return voteResponse(vote, *latestConflict)
-				}
-
-				return ""
-			}
+			return ctx, types.NewDisplayer("RegexInconsistencyVoteRespond", map[string]string{"seqno": seqno})
 		},
 	},
 
 	"RegexInconsistencyVoted": &types.LogRegex{
 		Regex: regexp.MustCompile("Inconsistency detected: Inconsistent by consensus"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "found inconsistent by vote"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "found inconsistent by vote")
 		},
 	},
 
@@ -135,22 +168,7 @@ var ApplicativeMap = types.RegexMap{
 			}
 			c.Winner = errormd5
 
-			return ctx, func(ctx types.LogCtx) string {
-				out := "consistency vote(seqno:" + c.Seqno + "): "
-				for _, name := range ctx.OwnNames {
-
-					vote, ok := c.VotePerNode[name]
-					if !ok {
-						continue
-					}
-
-					if vote.MD5 == c.Winner {
-						return out + utils.Paint(utils.GreenText, "won")
-					}
-					return out + utils.Paint(utils.RedText, "lost")
-				}
-				return ""
-			}
+			return ctx, types.NewDisplayer("RegexInconsistencyWinner", map[string]string{"seqno": c.Seqno})
 		},
 	},
 
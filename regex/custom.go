@@ -0,0 +1,228 @@
+package regex
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// CustomRules is the content of a rule file given with --rules
+// json being a subset of yaml, both formats are read the same way
+//
+//	rules:
+//	  - key: RegexCustomerBackupStarted
+//	    regex: "backup tool started"
+//	    internalRegex: "backup tool started by (?P<user>\\w+)"
+//	    type: events
+//	    verbosity: detailed
+//	    message: "backup started by {{.user}}"
+//	    color: yellow
+//	    flavor: pxc
+//	    effects:
+//	      state: DESYNCED
+type CustomRules struct {
+	Rules []CustomRule `yaml:"rules"`
+}
+
+type CustomRule struct {
+	Key           string          `yaml:"key"`
+	Regex         string          `yaml:"regex"`
+	InternalRegex string          `yaml:"internalRegex"`
+	Type          types.RegexType `yaml:"type"`
+	Verbosity     string          `yaml:"verbosity"`
+
+	// Message and effects are text/template, executed with the named groups of InternalRegex
+	Message string        `yaml:"message"`
+	Color   string        `yaml:"color"`
+	Effects CustomEffects `yaml:"effects"`
+
+	// Flavor restricts the rule to the logs of a single software
+	Flavor string `yaml:"flavor"`
+}
+
+// CustomEffects are the context changes a rule can declare
+// empty values are ignored
+type CustomEffects struct {
+	State     string `yaml:"state"`
+	SSTMethod string `yaml:"sstMethod"`
+	SSTType   string `yaml:"sstType"`
+	OwnName   string `yaml:"ownName"`
+	OwnIP     string `yaml:"ownIP"`
+}
+
+var customVerbosities = map[string]types.Verbosity{
+	"":           types.Info,
+	"info":       types.Info,
+	"detailed":   types.Detailed,
+	"debugmysql": types.DebugMySQL,
+	"debug":      types.Debug,
+}
+
+var customTypes = []types.RegexType{
+	types.EventsRegexType,
+	types.SSTRegexType,
+	types.ViewsRegexType,
+	types.IdentRegexType,
+	types.StatesRegexType,
+	types.PXCOperatorRegexType,
+	types.ApplicativeRegexType,
+}
+
+var customColors = map[string]utils.Color{
+	"red":     utils.RedText,
+	"green":   utils.GreenText,
+	"yellow":  utils.YellowText,
+	"blue":    utils.BlueText,
+	"magenta": utils.MagentaText,
+	"cyan":    utils.CyanText,
+}
+
+// LoadCustomRules reads rule files and builds the regexes they define
+func LoadCustomRules(paths []string) (types.RegexMap, error) {
+	regexes := types.RegexMap{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read rules")
+		}
+		rules := CustomRules{}
+		if err := yaml.UnmarshalStrict(content, &rules); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse rules from %s", path)
+		}
+		for _, rule := range rules.Rules {
+			if _, ok := regexes[rule.Key]; ok {
+				return nil, errors.Errorf("%s: rule %s is defined more than once", path, rule.Key)
+			}
+			r, err := rule.LogRegex()
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s: invalid rule %s", path, rule.Key)
+			}
+			regexes[rule.Key] = r
+		}
+	}
+	return regexes, nil
+}
+
+// LogRegex compiles a rule into the same regexes as the builtin ones
+func (rule CustomRule) LogRegex() (*types.LogRegex, error) {
+	if rule.Key == "" {
+		return nil, errors.New("key is required")
+	}
+	if rule.Regex == "" {
+		return nil, errors.New("regex is required")
+	}
+
+	r := &types.LogRegex{Type: rule.Type}
+	var err error
+	r.Regex, err = regexp.Compile(rule.Regex)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compile regex")
+	}
+	if rule.InternalRegex != "" {
+		r.InternalRegex, err = regexp.Compile(rule.InternalRegex)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compile internalRegex")
+		}
+	}
+
+	if r.Type == "" {
+		r.Type = types.EventsRegexType
+	}
+	found := false
+	for _, t := range customTypes {
+		found = found || t == r.Type
+	}
+	if !found {
+		return nil, errors.Errorf("unknown type %s", r.Type)
+	}
+
+	if rule.Flavor != "" && rule.Flavor != FlavorPXC && rule.Flavor != FlavorMariaDB {
+		return nil, errors.Errorf("unknown flavor %s, should be pxc or mariadb", rule.Flavor)
+	}
+	r.Flavor = rule.Flavor
+
+	verbosity, ok := customVerbosities[strings.ToLower(rule.Verbosity)]
+	if !ok {
+		return nil, errors.Errorf("unknown verbosity %s, should be info, detailed, debugmysql or debug", rule.Verbosity)
+	}
+	r.Verbosity = verbosity
+
+	color, ok := customColors[rule.Color]
+	if rule.Color != "" && !ok {
+		return nil, errors.Errorf("unknown color %s", rule.Color)
+	}
+
+	message, err := newCustomTemplate("message", rule.Message)
+	if err != nil {
+		return nil, err
+	}
+	effects := map[string]*template.Template{}
+	for name, value := range map[string]string{
+		"state":     rule.Effects.State,
+		"sstMethod": rule.Effects.SSTMethod,
+		"sstType":   rule.Effects.SSTType,
+		"ownName":   rule.Effects.OwnName,
+		"ownIP":     rule.Effects.OwnIP,
+	} {
+		if value == "" {
+			continue
+		}
+		effects[name], err = newCustomTemplate(name, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.Handler = func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+		if t, ok := effects["state"]; ok {
+			ctx.SetState(executeCustomTemplate(t, submatches))
+		}
+		if t, ok := effects["sstMethod"]; ok {
+			ctx.SST.Method = executeCustomTemplate(t, submatches)
+		}
+		if t, ok := effects["sstType"]; ok {
+			ctx.SST.Type = executeCustomTemplate(t, submatches)
+		}
+		if t, ok := effects["ownName"]; ok {
+			if name := executeCustomTemplate(t, submatches); name != "" {
+				ctx.AddOwnName(name)
+			}
+		}
+		if t, ok := effects["ownIP"]; ok {
+			if ip := executeCustomTemplate(t, submatches); ip != "" {
+				ctx.AddOwnIP(ip)
+			}
+		}
+
+		msg := executeCustomTemplate(message, submatches)
+		if msg == "" {
+			return ctx, nil
+		}
+		return ctx, types.PaintedDisplayer(color, msg)
+	}
+	return r, nil
+}
+
+func newCustomTemplate(name, value string) (*template.Template, error) {
+	t, err := template.New(name).Option("missingkey=zero").Parse(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s template", name)
+	}
+	return t, nil
+}
+
+// executeCustomTemplate returns an empty string on errors, the same way handlers ignore lines they cannot parse
+func executeCustomTemplate(t *template.Template, submatches map[string]string) string {
+	b := &bytes.Buffer{}
+	if err := t.Execute(b, submatches); err != nil {
+		return ""
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package regex
+
+import (
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+func TestCustomRule(t *testing.T) {
+	utils.SkipColor = true
+	tests := []struct {
+		name        string
+		rule        CustomRule
+		log         string
+		expectedOut string
+		expectedErr bool
+		check       func(types.LogCtx) bool
+	}{
+		{
+			name: "message with submatches",
+			rule: CustomRule{Key: "RegexTest", Regex: "backup", InternalRegex: `backup started by (?P<user>\w+)`, Message: "backup by {{.user}}", Color: "yellow"},
+			log:  "2001-01-01T01:01:01.000000Z 0 [Note] backup started by alice",
+
+			expectedOut: "backup by alice",
+		},
+		{
+			name: "effects",
+			rule: CustomRule{Key: "RegexTest", Regex: "custom_hostname", InternalRegex: `custom_hostname=(?P<name>\w+) ip=(?P<ip>[0-9.]+)`,
+				Effects: CustomEffects{State: "DONOR", SSTMethod: "clone", OwnName: "{{.name}}", OwnIP: "{{.ip}}"}},
+			log: "2001-01-01T01:01:01.000000Z 0 [Note] custom_hostname=node1 ip=10.0.0.1",
+			check: func(ctx types.LogCtx) bool {
+				return ctx.State() == "DONOR" && ctx.SST.Method == "clone" && utils.SliceContains(ctx.OwnNames, "node1") && utils.SliceContains(ctx.OwnIPs, "10.0.0.1")
+			},
+		},
+		{
+			name: "missing submatches are empty",
+			rule: CustomRule{Key: "RegexTest", Regex: "backup", Message: "backup by {{.user}}"},
+			log:  "backup",
+
+			expectedOut: "backup by ",
+		},
+		{
+			name:        "unknown verbosity",
+			rule:        CustomRule{Key: "RegexTest", Regex: "backup", Verbosity: "loud"},
+			expectedErr: true,
+		},
+		{
+			name:        "unknown type",
+			rule:        CustomRule{Key: "RegexTest", Regex: "backup", Type: "something"},
+			expectedErr: true,
+		},
+		{
+			name:        "unknown flavor",
+			rule:        CustomRule{Key: "RegexTest", Regex: "backup", Flavor: "mysql"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid regex",
+			rule:        CustomRule{Key: "RegexTest", Regex: "backup("},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid template",
+			rule:        CustomRule{Key: "RegexTest", Regex: "backup", Message: "{{.user"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		r, err := test.rule.LogRegex()
+		if test.expectedErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if r.Type != types.EventsRegexType {
+			t.Errorf("%s: expected default type events, got %s", test.name, r.Type)
+		}
+
+		ctx, displayer := r.Handle(types.NewLogCtx(), test.log)
+		out := ""
+		if displayer != nil {
+			out = displayer.Render(ctx)
+		}
+		if out != test.expectedOut {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expectedOut, out)
+		}
+		if test.check != nil && !test.check(ctx) {
+			t.Errorf("%s: unexpected ctx %+v", test.name, ctx)
+		}
+	}
+}
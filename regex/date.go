@@ -1,12 +1,11 @@
 package regex
 
 import (
-	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
-	"github.com/ylacancellera/galera-log-explainer/utils"
 )
 
 // 5.5 date : 151027  6:02:49
@@ -23,63 +22,122 @@ var DateLayouts = []string{
 	"2006/01/02 15:04:05",              // sometimes found in socat errors
 }
 
+// maxZoneOffset is the furthest a local time can be from UTC
+const maxZoneOffset = 14 * time.Hour
+
+// dateFilter describes how dates of a layout from DateLayouts are written, up to the minute
+type dateFilter struct {
+	fields []dateField
+	suffix string // what follows minutes, to tell apart layouts starting the same way
+
+	offset bool // dates are written with their offset, they are not necessarily in UTC
+	local  bool // dates are written in the server local time, without offsets
+}
+
+type dateField struct {
+	separator string // regex before the field
+	format    string // layout of the field: 2006, 06, 01, 02, 15, 04
+	padded    bool   // mysql can write hours as " 7" instead of "07"
+}
+
+var (
+	dateFields = []dateField{{format: "2006"}, {separator: "-", format: "01"}, {separator: "-", format: "02"}}
+	isoFields  = append(dateFields, dateField{separator: "T", format: "15"}, dateField{separator: ":", format: "04"})
+
+	// both 2006-01-02 15:04:05 and 2006-01-02  15:04:05
+	spacedFields = append(dateFields, dateField{separator: " +", format: "15", padded: true}, dateField{separator: ":", format: "04"})
+
+	dateFilters = []dateFilter{
+		{fields: isoFields, suffix: ":[0-9]{2}\\.[0-9]+Z"},
+		{fields: isoFields, suffix: ":[0-9]{2}\\.[0-9]+[-+]", offset: true},
+		{fields: []dateField{{format: "06"}, {format: "01"}, {format: "02"}, {separator: " +", format: "15", padded: true}, {separator: ":", format: "04"}}, local: true},
+		{fields: spacedFields, local: true},
+		{fields: []dateField{{format: "2006"}, {separator: "/", format: "01"}, {separator: "/", format: "02"}, {separator: " +", format: "15", padded: true}, {separator: ":", format: "04"}}, local: true},
+	}
+)
+
 // BetweenDateRegex generate a regex to filter mysql error log dates to just get
-// events between 2 dates
-// Currently limited to filter by day to produce "short" regexes. Finer events will be filtered later in code
-// Trying to filter hours, minutes using regexes would produce regexes even harder to read
-// while not really adding huge benefit as we do not expect so many events of interets
-func BetweenDateRegex(since *time.Time, skipLeadingCircumflex bool) string {
-	/*
-		"2006-01-02
-		"2006-01-0[3-9]
-		"2006-01-[1-9][0-9]
-		"2006-0[2-9]-[0-9]{2}
-		"2006-[1-9][0-9]-[0-9]{2}
-		"200[7-9]-[0-9]{2}-[0-9]{2}
-		"20[1-9][0-9]-[0-9]{2}-[0-9]{2}
-	*/
+// events after a date, for every layouts of DateLayouts
+// It is precise up to the minute, finer events will be filtered later in code
+//
+// Dates are compared field by field, eg for 2023-01-23T03:53:
+// a date is after when its year is greater, or when the year is the same and the month is greater, and so on.
+// Dates written with offsets, or in local time when zonesGiven is set, may not be in UTC:
+// they are compared with a margin, as the offset can't be known before the line is parsed
+func BetweenDateRegex(since *time.Time, skipLeadingCircumflex bool, zonesGiven bool) string {
 
 	separator := "|^"
 	if skipLeadingCircumflex {
 		separator = "|"
 	}
 
-	regexConstructor := []struct {
-		unit      int
-		unitToStr string
-	}{
-		{
-			unit:      since.Day(),
-			unitToStr: fmt.Sprintf("%02d", since.Day()),
-		},
-		{
-			unit:      int(since.Month()),
-			unitToStr: fmt.Sprintf("%02d", since.Month()),
-		},
-		{
-			unit:      since.Year(),
-			unitToStr: fmt.Sprintf("%d", since.Year())[2:],
-		},
-	}
 	s := ""
-	for _, layout := range []string{"2006-01-02", "060102"} {
-		// base complete date
-		lastTransformed := since.Format(layout)
-		s += separator + lastTransformed
-
-		for _, construct := range regexConstructor {
-			if construct.unit != 9 {
-				s += separator + utils.StringsReplaceReversed(lastTransformed, construct.unitToStr, string(construct.unitToStr[0])+"["+strconv.Itoa(construct.unit%10+1)+"-9]", 1)
+	for _, filter := range dateFilters {
+		t := since.UTC()
+		if filter.offset || (filter.local && zonesGiven) {
+			t = t.Add(-maxZoneOffset)
+		}
+		for _, alternative := range filter.after(t) {
+			s += separator + alternative
+		}
+	}
+	return "(" + s[1:] + ")"
+}
+
+// after returns regexes matching dates of this layout written after t, or at the same minute
+func (filter dateFilter) after(t time.Time) []string {
+	values := make([]string, len(filter.fields))
+	for i, field := range filter.fields {
+		values[i] = t.Format(field.format)
+	}
+
+	equal := ""
+	alternatives := []string{}
+	for i, field := range filter.fields {
+		rest := ""
+		for _, next := range filter.fields[i+1:] {
+			rest += next.anyValue()
+		}
+		for _, greater := range greaterDigits(values[i]) {
+			if field.padded && strings.HasPrefix(greater, "0") {
+				greater = "0?" + greater[1:]
 			}
-			// %1000 here is to cover the transformation of 2022 => 22
-			s += separator + utils.StringsReplaceReversed(lastTransformed, construct.unitToStr, "["+strconv.Itoa((construct.unit%1000/10)+1)+"-9][0-9]", 1)
+			alternatives = append(alternatives, equal+field.separator+greater+rest+filter.suffix)
+		}
+		equal += field.equalValue(values[i])
+	}
+	return append([]string{equal + filter.suffix}, alternatives...)
+}
+
+func (field dateField) equalValue(value string) string {
+	if field.padded && strings.HasPrefix(value, "0") {
+		return field.separator + "0?" + value[1:]
+	}
+	return field.separator + value
+}
 
-			lastTransformed = utils.StringsReplaceReversed(lastTransformed, construct.unitToStr, "[0-9][0-9]", 1)
+func (field dateField) anyValue() string {
+	if field.padded {
+		return field.separator + "[0-9]{1,2}"
+	}
+	return field.separator + "[0-9]{" + strconv.Itoa(len(field.format)) + "}"
+}
 
+// greaterDigits returns regexes matching every numbers strictly greater than value, written with the same number of digits
+// "0123" => [1-9][0-9]{3}, 0[2-9][0-9]{2}, 01[3-9][0-9]{1}, 012[4-9]
+func greaterDigits(value string) []string {
+	alternatives := []string{}
+	for i := range value {
+		if value[i] == '9' {
+			continue
 		}
+		alternative := value[:i] + "[" + string(value[i]+1) + "-9]"
+		if rest := len(value) - i - 1; rest > 0 {
+			alternative += "[0-9]{" + strconv.Itoa(rest) + "}"
+		}
+		alternatives = append(alternatives, alternative)
 	}
-	s += ")"
-	return "(" + s[1:]
+	return alternatives
 }
 
 // basically capturing anything that does not have a date
@@ -92,6 +150,11 @@ func NoDatesRegex(skipLeadingCircumflex bool) string {
 	return "^(?![0-9]{4})"
 }
 
+// DateLayoutHasZone is false for layouts where dates are written in the server local time, without offsets
+func DateLayoutHasZone(layout string) bool {
+	return strings.HasSuffix(layout, "Z") || strings.HasSuffix(layout, "-07:00")
+}
+
 /*
 SYSLOG_DATE="\(Jan\|Feb\|Mar\|Apr\|May\|Jun\|Jul\|Aug\|Sep\|Oct\|Nov\|Dec\) \( \|[0-9]\)[0-9] [0-9]\{2\}:[0-9]\{2\}:[0-9]\{2\}"
 REGEX_LOG_PREFIX="$REGEX_DATE \?[0-9]* "
@@ -100,9 +163,7 @@ REGEX_LOG_PREFIX="$REGEX_DATE \?[0-9]* "
 const k8sprefix = `{"log":"`
 
 func SearchDateFromLog(logline string) (time.Time, string, bool) {
-	if logline[:len(k8sprefix)] == k8sprefix {
-		logline = logline[len(k8sprefix):]
-	}
+	logline = strings.TrimPrefix(logline, k8sprefix)
 	for _, layout := range DateLayouts {
 		if len(logline) < len(layout) {
 			continue
@@ -1,44 +1,69 @@
 package regex
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestDateAfter(t *testing.T) {
-	tests := []struct {
-		input    string
-		format   string
-		expected string
-	}{
-		{
-			input:    "2006-01-02T15:04:05Z",
-			format:   time.RFC3339,
-			expected: "(^2006-01-02|^2006-01-0[3-9]|^2006-01-[1-9][0-9]|^2006-0[2-9]-[0-9][0-9]|^2006-[1-9][0-9]-[0-9][0-9]|^200[7-9]-[0-9][0-9]-[0-9][0-9]|^20[1-9][0-9]-[0-9][0-9]-[0-9][0-9]|^060102|^06010[3-9]|^0601[1-9][0-9]|^060[2-9][0-9][0-9]|^06[1-9][0-9][0-9][0-9]|^0[7-9][0-9][0-9][0-9][0-9]|^[1-9][0-9][0-9][0-9][0-9][0-9])",
-		},
-		{
-			input:    "2006-01-02",
-			format:   "2006-01-02",
-			expected: "(^2006-01-02|^2006-01-0[3-9]|^2006-01-[1-9][0-9]|^2006-0[2-9]-[0-9][0-9]|^2006-[1-9][0-9]-[0-9][0-9]|^200[7-9]-[0-9][0-9]-[0-9][0-9]|^20[1-9][0-9]-[0-9][0-9]-[0-9][0-9]|^060102|^06010[3-9]|^0601[1-9][0-9]|^060[2-9][0-9][0-9]|^06[1-9][0-9][0-9][0-9]|^0[7-9][0-9][0-9][0-9][0-9]|^[1-9][0-9][0-9][0-9][0-9][0-9])",
-		},
-		{
-			input:    "060102",
-			format:   "060102",
-			expected: "(^2006-01-02|^2006-01-0[3-9]|^2006-01-[1-9][0-9]|^2006-0[2-9]-[0-9][0-9]|^2006-[1-9][0-9]-[0-9][0-9]|^200[7-9]-[0-9][0-9]-[0-9][0-9]|^20[1-9][0-9]-[0-9][0-9]-[0-9][0-9]|^060102|^06010[3-9]|^0601[1-9][0-9]|^060[2-9][0-9][0-9]|^06[1-9][0-9][0-9][0-9]|^0[7-9][0-9][0-9][0-9][0-9]|^[1-9][0-9][0-9][0-9][0-9][0-9])",
-		},
-		{
-			input:    "2022-01-22",
-			format:   "2006-01-02",
-			expected: "(^2022-01-22|^2022-01-2[3-9]|^2022-01-[3-9][0-9]|^2022-0[2-9]-[0-9][0-9]|^2022-[1-9][0-9]-[0-9][0-9]|^202[3-9]-[0-9][0-9]-[0-9][0-9]|^20[3-9][0-9]-[0-9][0-9]-[0-9][0-9]|^220122|^22012[3-9]|^2201[3-9][0-9]|^220[2-9][0-9][0-9]|^22[1-9][0-9][0-9][0-9]|^2[3-9][0-9][0-9][0-9][0-9]|^[3-9][0-9][0-9][0-9][0-9][0-9])",
-		},
+	offsetZone := time.FixedZone("", -6*3600)
+	sinces := []time.Time{
+		time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+		time.Date(2022, time.January, 22, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, time.January, 23, 3, 53, 40, 0, time.UTC),
+		time.Date(2019, time.December, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2023, time.September, 9, 9, 9, 0, 0, time.UTC),
 	}
 
-	for _, test := range tests {
-		d, _ := time.Parse(test.format, test.input)
-		s := BetweenDateRegex(&d, false)
-		if s != test.expected {
-			t.Log("wrong date regex:", s)
-			t.Fail()
+	for _, since := range sinces {
+		since := since
+		re := regexp.MustCompile(BetweenDateRegex(&since, false, false))
+		sinceMinute := since.Truncate(time.Minute)
+
+		for d := -3 * 24 * time.Hour; d < 3*24*time.Hour; d += 17*time.Minute + 13*time.Second {
+			date := since.Add(d)
+			after := !date.Truncate(time.Minute).Before(sinceMinute)
+
+			logs := []string{}
+			for _, layout := range DateLayouts {
+				if DateLayoutHasZone(layout) {
+					continue
+				}
+				logs = append(logs, date.Format(layout))
+			}
+			logs = append(logs, date.Format("2006-01-02T15:04:05.000000Z"))
+			// mariadb and 5.5 pad hours with spaces
+			logs = append(logs, fmt.Sprintf("%s %2d:%s", date.Format("2006-01-02 "), date.Hour(), date.Format("04:05")))
+			logs = append(logs, fmt.Sprintf("%s %2d:%s", date.Format("060102"), date.Hour(), date.Format("04:05")))
+
+			for _, log := range logs {
+				if re.MatchString(log+" 0 [Note] WSREP: ...") != after {
+					t.Fatalf("since %s, log %s: expected match=%t", since, log, after)
+				}
+			}
+
+			// offsets are only known once parsed, there should just never be false negatives
+			log := date.In(offsetZone).Format("2006-01-02T15:04:05.000000-07:00")
+			if after && !re.MatchString(log) {
+				t.Fatalf("since %s, log %s: expected a match", since, log)
+			}
+			if date.Before(since.Add(-maxZoneOffset-time.Minute)) && re.MatchString(log) {
+				t.Fatalf("since %s, log %s: expected no match", since, log)
+			}
 		}
 	}
 }
+
+func TestGreaterDigits(t *testing.T) {
+	expected := []string{"[1-9][0-9]{3}", "0[2-9][0-9]{2}", "01[3-9][0-9]{1}", "012[4-9]"}
+	got := greaterDigits("0123")
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got := greaterDigits("99"); len(got) != 0 {
+		t.Errorf("expected nothing, got %v", got)
+	}
+}
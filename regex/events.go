@@ -16,25 +16,14 @@ var EventsMap = types.RegexMap{
 	"RegexStarting": &types.LogRegex{
 		Regex:         regexp.MustCompile("starting as process"),
 		InternalRegex: regexp.MustCompile("\\(mysqld " + regexVersion + ".*\\)"),
-		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			ctx.Version = submatches[groupVersion]
-
-			msg := "starting(" + ctx.Version
-			if isShutdownReasonMissing(ctx) {
-				msg += ", " + utils.Paint(utils.YellowText, "could not catch how/when it stopped")
-			}
-			msg += ")"
-			ctx.SetState("OPEN")
-
-			return ctx, types.SimpleDisplayer(msg)
-		},
+		Handler:       startingHandler,
 	},
 	"RegexShutdownComplete": &types.LogRegex{
 		Regex: regexp.MustCompile("mysqld: Shutdown complete"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("CLOSED")
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "shutdown complete"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "shutdown complete")
 		},
 	},
 	"RegexTerminated": &types.LogRegex{
@@ -42,21 +31,21 @@ var EventsMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("CLOSED")
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "terminated"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "terminated")
 		},
 	},
 	"RegexGotSignal6": &types.LogRegex{
 		Regex: regexp.MustCompile("mysqld got signal 6"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("CLOSED")
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "crash: got signal 6"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "crash: got signal 6")
 		},
 	},
 	"RegexGotSignal11": &types.LogRegex{
 		Regex: regexp.MustCompile("mysqld got signal 11"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("CLOSED")
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "crash: got signal 11"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "crash: got signal 11")
 		},
 	},
 	"RegexShutdownSignal": &types.LogRegex{
@@ -64,7 +53,7 @@ var EventsMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("CLOSED")
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "received shutdown"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "received shutdown")
 		},
 	},
 
@@ -75,7 +64,7 @@ var EventsMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("CLOSED")
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "ABORTING"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "ABORTING")
 		},
 	},
 
@@ -84,25 +73,32 @@ var EventsMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("OPEN")
 			if regexWsrepLoadNone.MatchString(log) {
-				return ctx, types.SimpleDisplayer(utils.Paint(utils.GreenText, "started(standalone)"))
+				return ctx, types.PaintedDisplayer(utils.GreenText, "started(standalone)")
 			}
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.GreenText, "started(cluster)"))
+			return ctx, types.PaintedDisplayer(utils.GreenText, "started(cluster)")
 		},
 	},
 	"RegexWsrepRecovery": &types.LogRegex{
 		//  INFO: WSREP: Recovered position 00000000-0000-0000-0000-000000000000:-1
-		Regex: regexp.MustCompile("Recovered position"),
+		Regex:         regexp.MustCompile("Recovered position"),
+		InternalRegex: regexp.MustCompile("Recovered position( from storage)?:? (" + regexUUID + ":(?P<" + groupSeqno + ">-?[0-9]+))?"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 
-			msg := "wsrep recovery"
+			msg := []types.Segment{types.Plain("wsrep recovery")}
+			seqno := submatches[groupSeqno]
+			ctx.SetPosition(submatches[groupUUID], seqno)
+			if seqno != "" && seqno != "-1" {
+				msg = append(msg, types.Plain("(seqno:"+seqno+")"))
+			}
+
 			// if state is joiner, it can be due to sst
 			// if state is open, it is just a start sequence depending on platform
 			if isShutdownReasonMissing(ctx) && ctx.State() != "JOINER" && ctx.State() != "OPEN" {
-				msg += "(" + utils.Paint(utils.YellowText, "could not catch how/when it stopped") + ")"
+				msg = append(msg, types.Plain("("), types.Painted(utils.YellowText, "could not catch how/when it stopped"), types.Plain(")"))
 			}
 			ctx.SetState("RECOVERY")
 
-			return ctx, types.SimpleDisplayer(msg)
+			return ctx, types.SegmentsDisplayer(msg...)
 		},
 	},
 
@@ -117,7 +113,7 @@ var EventsMap = types.RegexMap{
 			if len(v) > 20 {
 				v = v[:20] + "..."
 			}
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "unknown variable") + ": " + v)
+			return ctx, types.SegmentsDisplayer(types.Painted(utils.YellowText, "unknown variable"), types.Plain(": "+v))
 		},
 	},
 
@@ -126,7 +122,7 @@ var EventsMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("CLOSED")
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "ASSERTION FAILURE"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "ASSERTION FAILURE")
 		},
 	},
 	"RegexBindAddressAlreadyUsed": &types.LogRegex{
@@ -134,13 +130,13 @@ var EventsMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("CLOSED")
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "bind address already used"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "bind address already used")
 		},
 	},
 	"RegexTooManyConnections": &types.LogRegex{
 		Regex: regexp.MustCompile("Too many connections"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "too many connections"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "too many connections")
 		},
 	},
 
@@ -149,12 +145,26 @@ var EventsMap = types.RegexMap{
 		InternalRegex: regexp.MustCompile("Reversing history: " + regexSeqno + " -> [0-9]*, this member has applied (?P<diff>[0-9]*) more events than the primary component"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.BrightRedText, "having "+submatches["diff"]+" more events than the other nodes, data loss possible"))
+			return ctx, types.PaintedDisplayer(utils.BrightRedText, "having "+submatches["diff"]+" more events than the other nodes, data loss possible")
 		},
 	},
 }
 var regexWsrepLoadNone = regexp.MustCompile("none")
 
+// startingHandler is shared by every flavors' start banners
+func startingHandler(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+	ctx.Version = submatches[groupVersion]
+
+	msg := []types.Segment{types.Plain("starting(" + ctx.Version)}
+	if isShutdownReasonMissing(ctx) {
+		msg = append(msg, types.Plain(", "), types.Painted(utils.YellowText, "could not catch how/when it stopped"))
+	}
+	msg = append(msg, types.Plain(")"))
+	ctx.SetState("OPEN")
+
+	return ctx, types.SegmentsDisplayer(msg...)
+}
+
 // isShutdownReasonMissing is returning true if the latest wsrep state indicated a "working" node
 func isShutdownReasonMissing(ctx types.LogCtx) bool {
 	return ctx.State() != "DESTROYED" && ctx.State() != "CLOSED" && ctx.State() != "RECOVERY" && ctx.State() != ""
@@ -71,7 +71,7 @@ var IdentsMap = types.RegexMap{
 
 	"RegexMemberCount": &types.LogRegex{
 		Regex:         regexp.MustCompile("members.[0-9]+.:"),
-		InternalRegex: regexp.MustCompile(regexMembers),
+		InternalRegex: regexp.MustCompile("members." + regexMembers + ".:"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 
 			members := submatches[groupMembers]
@@ -0,0 +1,145 @@
+package regex
+
+import (
+	"regexp"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+const (
+	FlavorAuto    = "auto"
+	FlavorPXC     = "pxc"
+	FlavorMariaDB = "mariadb"
+)
+
+// MariaDBMap is the wording specific to MariaDB 10.4+ (mariadbd, mariabackup, provider loading)
+// Shared wording is already handled by the other maps
+// Regexes types are set one by one, as this map covers several of them: they are added to the map of their type
+// so that they are selected the same way as the others. Their flavor is set, files detected as pxc will skip them
+var MariaDBMap = types.RegexMap{
+	// 2023-05-04 10:00:00 0 [Note] Starting MariaDB 10.11.2-MariaDB-log source revision 8f6f4b6e as process 1
+	"RegexMariaDBStarting": &types.LogRegex{
+		Regex:         regexp.MustCompile("Starting MariaDB"),
+		InternalRegex: regexp.MustCompile("Starting MariaDB " + regexMariaDBVersion),
+		Handler:       startingHandler,
+		Type:          types.EventsRegexType,
+	},
+
+	// 2023-05-04 10:00:00 0 [Note] /usr/sbin/mariadbd (server 10.6.12-MariaDB-log) starting as process 1 ...
+	"RegexMariaDBServerStarting": &types.LogRegex{
+		Regex:         regexp.MustCompile("mariadbd \\(server .*starting as process"),
+		InternalRegex: regexp.MustCompile("\\(server " + regexMariaDBVersion + ".*\\)"),
+		Handler:       startingHandler,
+		Type:          types.EventsRegexType,
+	},
+
+	"RegexMariaDBShutdownComplete": &types.LogRegex{
+		Regex: regexp.MustCompile("mariadbd: Shutdown complete"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx.SetState("CLOSED")
+
+			return ctx, types.PaintedDisplayer(utils.RedText, "shutdown complete")
+		},
+		Type: types.EventsRegexType,
+	},
+
+	"RegexMariaDBGotSignal6": &types.LogRegex{
+		Regex: regexp.MustCompile("mariadbd got signal 6"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx.SetState("CLOSED")
+			return ctx, types.PaintedDisplayer(utils.RedText, "crash: got signal 6")
+		},
+		Type: types.EventsRegexType,
+	},
+	"RegexMariaDBGotSignal11": &types.LogRegex{
+		Regex: regexp.MustCompile("mariadbd got signal 11"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx.SetState("CLOSED")
+			return ctx, types.PaintedDisplayer(utils.RedText, "crash: got signal 11")
+		},
+		Type: types.EventsRegexType,
+	},
+
+	// 2023-05-04 10:00:00 0 [Note] WSREP: Loading provider /usr/lib/galera/libgalera_smm.so initial position: 6c2f5c1a-ea5e-11ed-8e4d-3a7c1e4a9d6b:12
+	// it comes right before the "wsrep_load(): loading provider library" handled by RegexWsrepLoad, so it does not change the state
+	"RegexMariaDBInitialPosition": &types.LogRegex{
+		Regex:         regexp.MustCompile("WSREP: Loading provider .* initial position"),
+		InternalRegex: regexp.MustCompile("initial position: " + regexUUID + ":(?P<" + groupSeqno + ">-?[0-9]+)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx.SetPosition(submatches[groupUUID], submatches[groupSeqno])
+			return ctx, types.SimpleDisplayer("initial position: " + submatches[groupUUID] + ":" + submatches[groupSeqno])
+		},
+		Type:      types.EventsRegexType,
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-05-04 10:00:00 3 [Note] WSREP: Running: 'wsrep_sst_mariabackup --role 'donor' --address '10.0.0.2:4444/xtrabackup_sst//1' ...
+	"RegexMariaDBSSTScript": &types.LogRegex{
+		Regex:         regexp.MustCompile("Running: 'wsrep_sst_"),
+		InternalRegex: regexp.MustCompile("Running: 'wsrep_sst_(?P<method>[a-z0-9_-]+) .*--role '(?P<role>donor|joiner)'"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			method := submatches["method"]
+			role := submatches["role"]
+			ctx.SST.Method = method
+
+			return ctx, types.SimpleDisplayer(method + " SST script started as " + role)
+		},
+		Type:      types.SSTRegexType,
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-05-04 10:00:00 0 [ERROR] WSREP: Process completed with error: wsrep_sst_mariabackup --role 'joiner' ...: 32 (Broken pipe)
+	// is already handled by RegexSSTError, this is the script side
+	// WSREP_SST: [ERROR] ******************* FATAL ERROR ********************** (20230504 10:00:00.000)
+	"RegexMariaDBSSTFatalError": &types.LogRegex{
+		Regex: regexp.MustCompile("WSREP_SST: \\[ERROR\\] \\*+ FATAL ERROR"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return ctx, types.PaintedDisplayer(utils.RedText, "SST script fatal error")
+		},
+		Type: types.SSTRegexType,
+	},
+
+	// WSREP_SST: [INFO] Streaming with mbstream (20230504 10:00:00.000)
+	"RegexMariabackupStreaming": &types.LogRegex{
+		Regex:         regexp.MustCompile("WSREP_SST: \\[INFO\\] Streaming with"),
+		InternalRegex: regexp.MustCompile("Streaming with (?P<format>[a-z]+)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return ctx, types.SimpleDisplayer("mariabackup streaming with " + submatches["format"])
+		},
+		Type:      types.SSTRegexType,
+		Verbosity: types.DebugMySQL,
+	},
+}
+
+func init() {
+	setFlavor(FlavorMariaDB, MariaDBMap)
+	for key, r := range MariaDBMap {
+		switch r.Type {
+		case types.EventsRegexType:
+			EventsMap[key] = r
+		case types.SSTRegexType:
+			SSTMap[key] = r
+		}
+	}
+}
+
+var (
+	// mariadb minor versions go beyond 9, 10.11 being a LTS
+	regexMariaDBVersion = "(?P<" + groupVersion + ">(10|11)\\.[0-9]{1,2}\\.[0-9]{1,2})"
+
+	regexMariaDBBanner = regexp.MustCompile("Starting MariaDB|mariadbd \\(server|[0-9]-MariaDB")
+	regexMySQLBanner   = regexp.MustCompile("\\(mysqld " + regexVersion + ".*\\) starting as process")
+)
+
+// DetectFlavor uses start banners to know which software produced the log
+// It returns an empty string when the line is not a banner
+func DetectFlavor(line string) string {
+	if regexMariaDBBanner.MatchString(line) {
+		return FlavorMariaDB
+	}
+	if regexMySQLBanner.MatchString(line) {
+		return FlavorPXC
+	}
+	return ""
+}
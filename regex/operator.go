@@ -9,6 +9,7 @@ import (
 
 func init() {
 	setType(types.PXCOperatorRegexType, PXCOperatorMap)
+	setFlavor(FlavorPXC, PXCOperatorMap)
 }
 
 // Regexes from this type should only be about operator extra logs
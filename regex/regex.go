@@ -24,6 +24,12 @@ func setType(t types.RegexType, regexes types.RegexMap) {
 	return
 }
 
+func setFlavor(flavor string, regexes types.RegexMap) {
+	for _, regex := range regexes {
+		regex.Flavor = flavor
+	}
+}
+
 // SetVerbosity accepts any LogRegex
 // Some can be useful to construct context, but we can choose not to display them
 func SetVerbosity(verbosity types.Verbosity, regexes types.RegexMap) {
@@ -66,6 +72,17 @@ var (
 	regexErrorMD5      = "(?P<" + groupErrorMD5 + ">[a-z0-9]*)"
 )
 
+// group names that can be used on the result of LogRegex.Submatches
+// useful to analyze a timeline after the fact
+var (
+	GroupNodeName  = groupNodeName
+	GroupNodeName2 = groupNodeName2
+	GroupSeqno     = groupSeqno
+	GroupNodeHash  = groupNodeHash
+	GroupNodeIP    = groupNodeIP
+	GroupUUID      = groupUUID
+)
+
 func IsNodeUUID(s string) bool {
 	b, _ := regexp.MatchString(regexUUID, s)
 	return b
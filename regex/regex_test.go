@@ -114,6 +114,40 @@ func TestRegexes(t *testing.T) {
 			mapToTest:     EventsMap,
 			key:           "RegexStarting",
 		},
+		{
+			name:          "mariadb 10.11 banner",
+			log:           "2001-01-01  1:01:01 0 [Note] Starting MariaDB 10.11.2-MariaDB-log source revision 8f6f4b6e as process 1",
+			expectedCtx:   types.LogCtx{Version: "10.11.2"},
+			expectedState: "OPEN",
+			expectedOut:   "starting(10.11.2)",
+			mapToTest:     MariaDBMap,
+			key:           "RegexMariaDBStarting",
+		},
+		{
+			name:          "mariadb 11.x banner",
+			log:           "2001-01-01  1:01:01 0 [Note] Starting MariaDB 11.2.2-MariaDB-log source revision 929532a9426d085111c24c63de9c23cc54382259 as process 1",
+			expectedCtx:   types.LogCtx{Version: "11.2.2"},
+			expectedState: "OPEN",
+			expectedOut:   "starting(11.2.2)",
+			mapToTest:     MariaDBMap,
+			key:           "RegexMariaDBStarting",
+		},
+		{
+			name:          "mariadb 10.6 banner",
+			log:           "2001-01-01 01:01:01 0 [Note] /usr/sbin/mariadbd (server 10.6.12-MariaDB-log) starting as process 1 ...",
+			expectedCtx:   types.LogCtx{Version: "10.6.12"},
+			expectedState: "OPEN",
+			expectedOut:   "starting(10.6.12)",
+			mapToTest:     MariaDBMap,
+			key:           "RegexMariaDBServerStarting",
+		},
+		{
+			name:                 "mariadb banner handled by pxc regex",
+			log:                  "2001-01-01 01:01:01 0 [Note] /usr/sbin/mariadbd (server 10.6.12-MariaDB-log) starting as process 1 ...",
+			displayerExpectedNil: true,
+			mapToTest:            EventsMap,
+			key:                  "RegexStarting",
+		},
 
 		{
 
@@ -209,15 +243,17 @@ func TestRegexes(t *testing.T) {
 
 		{
 			log:           "2001-01-01T01:01:01.000000Z 3 [Note] [MY-000000] [Galera] Recovered position from storage: 7780bb61-87cf-11eb-b53b-6a7c64b0fee3:23506640",
+			expectedCtx:   types.LogCtx{ClusterUUID: "7780bb61-87cf-11eb-b53b-6a7c64b0fee3", Seqno: "23506640"},
 			expectedState: "RECOVERY",
-			expectedOut:   "wsrep recovery",
+			expectedOut:   "wsrep recovery(seqno:23506640)",
 			mapToTest:     EventsMap,
 			key:           "RegexWsrepRecovery",
 		},
 		{
 			log:           " INFO: WSREP: Recovered position 9a4db4a5-5cf1-11ec-940d-6ba8c5905c02:30",
+			expectedCtx:   types.LogCtx{ClusterUUID: "9a4db4a5-5cf1-11ec-940d-6ba8c5905c02", Seqno: "30"},
 			expectedState: "RECOVERY",
-			expectedOut:   "wsrep recovery",
+			expectedOut:   "wsrep recovery(seqno:30)",
 			mapToTest:     EventsMap,
 			key:           "RegexWsrepRecovery",
 		},
@@ -439,6 +475,14 @@ func TestRegexes(t *testing.T) {
 			mapToTest:   IdentsMap,
 			key:         "RegexMemberCount",
 		},
+		{
+			name:        "the date should not be used as member count",
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera]  members(3):",
+			expectedOut: "view member count: 3",
+			expectedCtx: types.LogCtx{MemberCount: 3},
+			mapToTest:   IdentsMap,
+			key:         "RegexMemberCount",
+		},
 
 		{
 			log:      "2001-01-01T01:01:01.000000Z 1 [Note] [MY-000000] [Galera] ####### My UUID: 60205de0-5cf6-11ec-8884-3a01908be11a",
@@ -708,15 +752,60 @@ func TestRegexes(t *testing.T) {
 			key:         "RegexBootstrapingDefaultState",
 		},
 
+		{
+			log:         "2001-01-01T01:01:01.000000Z 7 [Note] WSREP: New cluster view: global state: 8c9b5610-e020-11ed-a5ea-e253cc5f629d:20, view# 10: Primary, number of nodes: 2, my index: 0, protocol version 3",
+			expectedCtx: types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "20"},
+			expectedOut: "view position(seqno:20)",
+			mapToTest:   ViewsMap,
+			key:         "RegexNewClusterView",
+		},
+		{
+			name:                 "non-primary views keep the last known position",
+			log:                  "2001-01-01T01:01:01.000000Z 7 [Note] WSREP: New cluster view: global state: 8c9b5610-e020-11ed-a5ea-e253cc5f629d:-1, view# -1: non-Primary, number of nodes: 1, my index: 0, protocol version 3",
+			inputCtx:             types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "20"},
+			expectedCtx:          types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "20"},
+			displayerExpectedNil: true,
+			mapToTest:            ViewsMap,
+			key:                  "RegexNewClusterView",
+		},
+		{
+			log:         "  id: 8c9b5610-e020-11ed-a5ea-e253cc5f629d:21",
+			expectedCtx: types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "21"},
+			expectedOut: "view position(seqno:21)",
+			mapToTest:   ViewsMap,
+			key:         "RegexViewPosition",
+		},
+		{
+			name:        "another cluster",
+			log:         "  id: 00c4fff1-c4b0-11e9-96a8-0f9789de42ad:3",
+			inputCtx:    types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "21"},
+			expectedCtx: types.LogCtx{ClusterUUID: "00c4fff1-c4b0-11e9-96a8-0f9789de42ad", Seqno: "3"},
+			expectedOut: "view position(seqno:3)",
+			mapToTest:   ViewsMap,
+			key:         "RegexViewPosition",
+		},
+
 		{
 			log:           "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: Shifting OPEN -> CLOSED (TO: 1922878)",
+			expectedCtx:   types.LogCtx{Seqno: "1922878"},
 			expectedState: "CLOSED",
 			expectedOut:   "OPEN -> CLOSED",
 			mapToTest:     StatesMap,
 			key:           "RegexShift",
 		},
+		{
+			name:          "TO is not known yet",
+			log:           "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] Shifting CLOSED -> OPEN (TO: 0)",
+			inputCtx:      types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "20"},
+			expectedCtx:   types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "20"},
+			expectedState: "OPEN",
+			expectedOut:   "CLOSED -> OPEN",
+			mapToTest:     StatesMap,
+			key:           "RegexShift",
+		},
 		{
 			log:           "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 21582507)",
+			expectedCtx:   types.LogCtx{Seqno: "21582507"},
 			expectedState: "DONOR",
 			expectedOut:   "SYNCED -> DONOR",
 			mapToTest:     StatesMap,
@@ -724,6 +813,7 @@ func TestRegexes(t *testing.T) {
 		},
 		{
 			log:           "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: Shifting DONOR/DESYNCED -> JOINED (TO: 21582507)",
+			expectedCtx:   types.LogCtx{Seqno: "21582507"},
 			expectedState: "JOINED",
 			expectedOut:   "DESYNCED -> JOINED",
 			mapToTest:     StatesMap,
@@ -732,6 +822,7 @@ func TestRegexes(t *testing.T) {
 
 		{
 			log:           "2001-01-01 01:01:01 140446385440512 [Note] WSREP: Restored state OPEN -> SYNCED (72438094)",
+			expectedCtx:   types.LogCtx{Seqno: "72438094"},
 			expectedState: "SYNCED",
 			expectedOut:   "(restored)OPEN -> SYNCED",
 			mapToTest:     StatesMap,
@@ -950,7 +1041,7 @@ func TestRegexes(t *testing.T) {
 
 		{
 			log:         "2001-01-01 01:01:01 140446376740608 [Note] WSREP: IST received: e00c4fff-c4b0-11e9-96a8-0f9789de42ad:69472531",
-			expectedCtx: types.LogCtx{},
+			expectedCtx: types.LogCtx{ClusterUUID: "e00c4fff-c4b0-11e9-96a8-0f9789de42ad", Seqno: "69472531"},
 			expectedOut: "IST received(seqno:69472531)",
 			mapToTest:   SSTMap,
 			key:         "RegexISTReceived",
@@ -958,7 +1049,7 @@ func TestRegexes(t *testing.T) {
 
 		{
 			log:           "2001-01-01  1:01:01 140433613571840 [Note] WSREP: async IST sender starting to serve tcp://172.17.0.2:4568 sending 2-116",
-			expectedCtx:   types.LogCtx{SST: types.SST{Type: "IST"}},
+			expectedCtx:   types.LogCtx{Seqno: "116", SST: types.SST{Type: "IST"}},
 			expectedState: "DONOR",
 			expectedOut:   "IST to 172.17.0.2(seqno:116)",
 			mapToTest:     SSTMap,
@@ -1130,14 +1221,14 @@ func TestRegexes(t *testing.T) {
 		{
 			log:         "{\"log\":\"2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] Member 1(node1) initiates vote on 8c9b5610-e020-11ed-a5ea-e253cc5f629d:20,bdb2b9234ae75cb3:  some error, Error_code: 123;\n\",\"file\":\"/var/lib/mysql/mysqld-error.log\"}",
 			expectedOut: "inconsistency vote started by node1(seqno:20)",
-			expectedCtx: types.LogCtx{Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}}}}},
+			expectedCtx: types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "20", Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}}}}},
 			mapToTest:   ApplicativeMap,
 			key:         "RegexInconsistencyVoteInit",
 		},
 		{
 			log:         "{\"log\":\"2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] Member 1(node1) initiates vote on 8c9b5610-e020-11ed-a5ea-e253cc5f629d:20,bdb2b9234ae75cb3:  some error, Error_code: 123;\n\",\"file\":\"/var/lib/mysql/mysqld-error.log\"}",
 			inputCtx:    types.LogCtx{OwnNames: []string{"node1"}},
-			expectedCtx: types.LogCtx{OwnNames: []string{"node1"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}}}}},
+			expectedCtx: types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "20", OwnNames: []string{"node1"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}}}}},
 			expectedOut: "inconsistency vote started(seqno:20)",
 			mapToTest:   ApplicativeMap,
 			key:         "RegexInconsistencyVoteInit",
@@ -1146,7 +1237,7 @@ func TestRegexes(t *testing.T) {
 		{
 			log:         "{\"log\":\"2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] Member 2(node2) responds to vote on 8c9b5610-e020-11ed-a5ea-e253cc5f629d:20,0000000000000000: Success\n\",\"file\":\"/var/lib/mysql/mysqld-error.log\"}",
 			inputCtx:    types.LogCtx{OwnNames: []string{"node2"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}}}}},
-			expectedCtx: types.LogCtx{OwnNames: []string{"node2"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}, "node2": types.ConflictVote{MD5: "0000000000000000", Error: "Success"}}}}},
+			expectedCtx: types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "20", OwnNames: []string{"node2"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}, "node2": types.ConflictVote{MD5: "0000000000000000", Error: "Success"}}}}},
 			expectedOut: "consistency vote(seqno:20): voted Success",
 			mapToTest:   ApplicativeMap,
 			key:         "RegexInconsistencyVoteRespond",
@@ -1154,7 +1245,7 @@ func TestRegexes(t *testing.T) {
 		{
 			log:         "{\"log\":\"2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] Member 2(node2) responds to vote on 8c9b5610-e020-11ed-a5ea-e253cc5f629d:20,bdb2b9234ae75cb3: some error\n\",\"file\":\"/var/lib/mysql/mysqld-error.log\"}",
 			inputCtx:    types.LogCtx{OwnNames: []string{"node2"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}}}}},
-			expectedCtx: types.LogCtx{OwnNames: []string{"node2"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}, "node2": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}}}}},
+			expectedCtx: types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "20", OwnNames: []string{"node2"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}, "node2": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}}}}},
 			expectedOut: "consistency vote(seqno:20): voted same error",
 			mapToTest:   ApplicativeMap,
 			key:         "RegexInconsistencyVoteRespond",
@@ -1163,7 +1254,7 @@ func TestRegexes(t *testing.T) {
 			// could not actually find a "responds to" with any error for now
 			log:         "{\"log\":\"2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] Member 2(node2) responds to vote on 8c9b5610-e020-11ed-a5ea-e253cc5f629d:20,ed9774a3cad44656: some different error\n\",\"file\":\"/var/lib/mysql/mysqld-error.log\"}",
 			inputCtx:    types.LogCtx{OwnNames: []string{"node2"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}}}}},
-			expectedCtx: types.LogCtx{OwnNames: []string{"node2"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}, "node2": types.ConflictVote{MD5: "ed9774a3cad44656", Error: "some different error"}}}}},
+			expectedCtx: types.LogCtx{ClusterUUID: "8c9b5610-e020-11ed-a5ea-e253cc5f629d", Seqno: "20", OwnNames: []string{"node2"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Seqno: "20", VotePerNode: map[string]types.ConflictVote{"node1": types.ConflictVote{MD5: "bdb2b9234ae75cb3", Error: "some error"}, "node2": types.ConflictVote{MD5: "ed9774a3cad44656", Error: "some different error"}}}}},
 			expectedOut: "consistency vote(seqno:20): voted different error",
 			mapToTest:   ApplicativeMap,
 			key:         "RegexInconsistencyVoteRespond",
@@ -1218,6 +1309,60 @@ func TestRegexes(t *testing.T) {
 			mapToTest:   ApplicativeMap,
 			key:         "RegexInconsistencyRecovery",
 		},
+
+		{
+			log:           "2001-01-01 01:01:01 0 [Note] /usr/sbin/mariadbd: Shutdown complete",
+			expectedState: "CLOSED",
+			expectedOut:   "shutdown complete",
+			mapToTest:     MariaDBMap,
+			key:           "RegexMariaDBShutdownComplete",
+		},
+		{
+			log:           "2001-01-01 01:01:01 0 [ERROR] mariadbd got signal 11 ;",
+			expectedState: "CLOSED",
+			expectedOut:   "crash: got signal 11",
+			mapToTest:     MariaDBMap,
+			key:           "RegexMariaDBGotSignal11",
+		},
+		{
+			log:           "2001-01-01 01:01:01 0 [ERROR] mariadbd got signal 6 ;",
+			expectedState: "CLOSED",
+			expectedOut:   "crash: got signal 6",
+			mapToTest:     MariaDBMap,
+			key:           "RegexMariaDBGotSignal6",
+		},
+		{
+			log:         "2001-01-01 01:01:01 0 [Note] WSREP: Loading provider /usr/lib/galera/libgalera_smm.so initial position: 6c2f5c1a-ea5e-11ed-8e4d-3a7c1e4a9d6b:12",
+			expectedCtx: types.LogCtx{ClusterUUID: "6c2f5c1a-ea5e-11ed-8e4d-3a7c1e4a9d6b", Seqno: "12"},
+			expectedOut: "initial position: 6c2f5c1a-ea5e-11ed-8e4d-3a7c1e4a9d6b:12",
+			mapToTest:   MariaDBMap,
+			key:         "RegexMariaDBInitialPosition",
+		},
+		{
+			log:         "2001-01-01 01:01:01 0 [Note] WSREP: Loading provider none initial position: 00000000-0000-0000-0000-000000000000:-1",
+			expectedOut: "initial position: 00000000-0000-0000-0000-000000000000:-1",
+			mapToTest:   MariaDBMap,
+			key:         "RegexMariaDBInitialPosition",
+		},
+		{
+			log:         "2001-01-01 01:01:01 3 [Note] WSREP: Running: 'wsrep_sst_mariabackup --role 'donor' --address '172.17.0.3:4444/xtrabackup_sst//1' --local-port 3306 --socket '/run/mysqld/mysqld.sock' --datadir '/var/lib/mysql/' --gtid '6c2f5c1a-ea5e-11ed-8e4d-3a7c1e4a9d6b:12' --gtid-domain-id 0 --mysqld-args --wsrep_start_position=6c2f5c1a-ea5e-11ed-8e4d-3a7c1e4a9d6b:12'",
+			expectedCtx: types.LogCtx{SST: types.SST{Method: "mariabackup"}},
+			expectedOut: "mariabackup SST script started as donor",
+			mapToTest:   MariaDBMap,
+			key:         "RegexMariaDBSSTScript",
+		},
+		{
+			log:         "WSREP_SST: [ERROR] ******************* FATAL ERROR ********************** (20010101 01:01:01.000)",
+			expectedOut: "SST script fatal error",
+			mapToTest:   MariaDBMap,
+			key:         "RegexMariaDBSSTFatalError",
+		},
+		{
+			log:         "WSREP_SST: [INFO] Streaming with mbstream (20010101 01:01:01.000)",
+			expectedOut: "mariabackup streaming with mbstream",
+			mapToTest:   MariaDBMap,
+			key:         "RegexMariabackupStreaming",
+		},
 	}
 
 	for _, test := range tests {
@@ -1242,7 +1387,7 @@ func TestRegexes(t *testing.T) {
 		ctx, displayer := test.mapToTest[test.key].Handle(test.inputCtx, test.log)
 		msg := ""
 		if displayer != nil {
-			msg = displayer(ctx)
+			msg = displayer.Render(ctx)
 		} else if !test.displayerExpectedNil {
 			t.Errorf("key: %s\ntestname: %s\ndisplayer is nil\nexpected: not nil", test.key, test.name)
 		}
@@ -1284,3 +1429,22 @@ func testActualGrepOnLog(t *testing.T, log, regex string) error {
 	}
 	return nil
 }
+
+func TestDetectFlavor(t *testing.T) {
+	tests := []struct {
+		log      string
+		expected string
+	}{
+		{log: "2001-01-01  1:01:01 0 [Note] Starting MariaDB 11.2.2-MariaDB-log source revision 929532a9 as process 1", expected: FlavorMariaDB},
+		{log: "2001-01-01 01:01:01 0 [Note] /usr/sbin/mariadbd (server 10.6.12-MariaDB-log) starting as process 1 ...", expected: FlavorMariaDB},
+		{log: "2001-01-01  01:01:01 0 [Note] /usr/sbin/mysqld (mysqld 10.4.25-MariaDB-log) starting as process 2 ...", expected: FlavorMariaDB},
+		{log: "2001-01-01T01:01:01.000000Z 0 [System] [MY-010116] [Server] /usr/sbin/mysqld (mysqld 8.0.30-22) starting as process 1", expected: FlavorPXC},
+		{log: "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] Shifting OPEN -> PRIMARY (TO: 0)", expected: ""},
+	}
+
+	for _, test := range tests {
+		if flavor := DetectFlavor(test.log); flavor != test.expected {
+			t.Errorf("log: %s, expected %q, got %q", test.log, test.expected, flavor)
+		}
+	}
+}
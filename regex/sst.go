@@ -9,6 +9,30 @@ import (
 
 func init() {
 	setType(types.SSTRegexType, SSTMap)
+
+	types.RegisterDisplayer("RegexSSTRequestSuccess", func(ctx types.LogCtx, params map[string]string) string {
+		joiner, donor := params["joiner"], params["donor"]
+		if utils.SliceContains(ctx.OwnNames, joiner) {
+			return donor + utils.Paint(utils.GreenText, " will resync local node")
+		}
+		if utils.SliceContains(ctx.OwnNames, donor) {
+			return utils.Paint(utils.GreenText, "local node will resync ") + joiner
+		}
+
+		return donor + utils.Paint(utils.GreenText, " will resync ") + joiner
+	})
+
+	types.RegisterDisplayer("RegexSSTComplete", func(ctx types.LogCtx, params map[string]string) string {
+		joiner, donor, displayType := params["joiner"], params["donor"], params["type"]
+		if utils.SliceContains(ctx.OwnNames, joiner) {
+			return utils.Paint(utils.GreenText, "got "+displayType+" from ") + donor
+		}
+		if utils.SliceContains(ctx.OwnNames, donor) {
+			return utils.Paint(utils.GreenText, "finished sending "+displayType+" to ") + joiner
+		}
+
+		return donor + utils.Paint(utils.GreenText, " synced ") + joiner
+	})
 }
 
 var SSTMap = types.RegexMap{
@@ -27,16 +51,7 @@ var SSTMap = types.RegexMap{
 				ctx.SST.ResyncingNode = joiner
 			}
 
-			return ctx, func(ctx types.LogCtx) string {
-				if utils.SliceContains(ctx.OwnNames, joiner) {
-					return donor + utils.Paint(utils.GreenText, " will resync local node")
-				}
-				if utils.SliceContains(ctx.OwnNames, donor) {
-					return utils.Paint(utils.GreenText, "local node will resync ") + joiner
-				}
-
-				return donor + utils.Paint(utils.GreenText, " will resync ") + joiner
-			}
+			return ctx, types.NewDisplayer("RegexSSTRequestSuccess", map[string]string{"joiner": joiner, "donor": donor})
 		},
 		Verbosity: types.Detailed,
 	},
@@ -49,10 +64,10 @@ var SSTMap = types.RegexMap{
 			joiner := submatches[groupNodeName]
 			if utils.SliceContains(ctx.OwnNames, joiner) {
 
-				return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "cannot find donor"))
+				return ctx, types.PaintedDisplayer(utils.YellowText, "cannot find donor")
 			}
 
-			return ctx, types.SimpleDisplayer(joiner + utils.Paint(utils.YellowText, " cannot find donor"))
+			return ctx, types.SegmentsDisplayer(types.Plain(joiner), types.Painted(utils.YellowText, " cannot find donor"))
 		},
 	},
 
@@ -72,16 +87,7 @@ var SSTMap = types.RegexMap{
 
 			ctx = addOwnNameWithSSTMetadata(ctx, joiner, donor)
 
-			return ctx, func(ctx types.LogCtx) string {
-				if utils.SliceContains(ctx.OwnNames, joiner) {
-					return utils.Paint(utils.GreenText, "got "+displayType+" from ") + donor
-				}
-				if utils.SliceContains(ctx.OwnNames, donor) {
-					return utils.Paint(utils.GreenText, "finished sending "+displayType+" to ") + joiner
-				}
-
-				return donor + utils.Paint(utils.GreenText, " synced ") + joiner
-			}
+			return ctx, types.NewDisplayer("RegexSSTComplete", map[string]string{"joiner": joiner, "donor": donor, "type": displayType})
 		},
 	},
 
@@ -94,7 +100,7 @@ var SSTMap = types.RegexMap{
 
 			donor := utils.ShortNodeName(submatches[groupNodeName])
 			ctx = addOwnNameWithSSTMetadata(ctx, "", donor)
-			return ctx, types.SimpleDisplayer(donor + utils.Paint(utils.RedText, " synced ??(node left)"))
+			return ctx, types.SegmentsDisplayer(types.Plain(donor), types.Painted(utils.RedText, " synced ??(node left)"))
 		},
 	},
 
@@ -105,7 +111,7 @@ var SSTMap = types.RegexMap{
 
 			donor := utils.ShortNodeName(submatches[groupNodeName])
 			ctx = addOwnNameWithSSTMetadata(ctx, "", donor)
-			return ctx, types.SimpleDisplayer(donor + utils.Paint(utils.RedText, " failed to sync ??(node left)"))
+			return ctx, types.SegmentsDisplayer(types.Plain(donor), types.Painted(utils.RedText, " failed to sync ??(node left)"))
 		},
 	},
 
@@ -117,7 +123,7 @@ var SSTMap = types.RegexMap{
 			donor := utils.ShortNodeName(submatches[groupNodeName])
 			joiner := utils.ShortNodeName(submatches[groupNodeName2])
 			ctx = addOwnNameWithSSTMetadata(ctx, joiner, donor)
-			return ctx, types.SimpleDisplayer(donor + utils.Paint(utils.RedText, " failed to sync ") + joiner)
+			return ctx, types.SegmentsDisplayer(types.Plain(donor), types.Painted(utils.RedText, " failed to sync "), types.Plain(joiner))
 		},
 	},
 
@@ -125,7 +131,7 @@ var SSTMap = types.RegexMap{
 		Regex: regexp.MustCompile("Process completed with error: wsrep_sst"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "SST error"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "SST error")
 		},
 	},
 
@@ -133,7 +139,7 @@ var SSTMap = types.RegexMap{
 		Regex: regexp.MustCompile("Initiating SST cancellation"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "former SST cancelled"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "former SST cancelled")
 		},
 	},
 
@@ -143,7 +149,7 @@ var SSTMap = types.RegexMap{
 			ctx.SetState("JOINER")
 			ctx.SST.Type = "SST"
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "receiving SST"))
+			return ctx, types.PaintedDisplayer(utils.YellowText, "receiving SST")
 		},
 	},
 
@@ -158,9 +164,7 @@ var SSTMap = types.RegexMap{
 				ctx.SST.ResyncingNode = node
 			}
 
-			return ctx, func(ctx types.LogCtx) string {
-				return utils.Paint(utils.YellowText, "SST to ") + types.DisplayNodeSimplestForm(ctx, node)
-			}
+			return ctx, types.SegmentsDisplayer(types.Painted(utils.YellowText, "SST to "), types.Node(node))
 		},
 	},
 
@@ -172,7 +176,8 @@ var SSTMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 
 			seqno := submatches[groupSeqno]
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.GreenText, "IST received") + "(seqno:" + seqno + ")")
+			ctx.SetPosition(submatches[groupUUID], seqno)
+			return ctx, types.SegmentsDisplayer(types.Painted(utils.GreenText, "IST received"), types.Plain("(seqno:"+seqno+")"))
 		},
 	},
 
@@ -185,12 +190,12 @@ var SSTMap = types.RegexMap{
 			ctx.SST.Type = "IST"
 			ctx.SetState("DONOR")
 
+			// the donor has at least every writesets it sends
 			seqno := submatches[groupSeqno]
+			ctx.SetPosition("", seqno)
 			node := submatches[groupNodeIP]
 
-			return ctx, func(ctx types.LogCtx) string {
-				return utils.Paint(utils.YellowText, "IST to ") + types.DisplayNodeSimplestForm(ctx, node) + "(seqno:" + seqno + ")"
-			}
+			return ctx, types.SegmentsDisplayer(types.Painted(utils.YellowText, "IST to "), types.Node(node), types.Plain("(seqno:"+seqno+")"))
 		},
 	},
 
@@ -202,7 +207,7 @@ var SSTMap = types.RegexMap{
 			ctx.SetState("JOINER")
 
 			seqno := submatches[groupSeqno]
-			msg := utils.Paint(utils.YellowText, "will receive ")
+			msg := ""
 
 			startingseqno := submatches["startingseqno"]
 			// if it's 0, it will go to SST without a doubt
@@ -219,7 +224,7 @@ var SSTMap = types.RegexMap{
 					msg += "(seqno:" + seqno + ")"
 				}
 			}
-			return ctx, types.SimpleDisplayer(msg)
+			return ctx, types.SegmentsDisplayer(types.Painted(utils.YellowText, "will receive "), types.Plain(msg))
 		},
 	},
 
@@ -243,7 +248,7 @@ var SSTMap = types.RegexMap{
 	"RegexSocatConnRefused": &types.LogRegex{
 		Regex: regexp.MustCompile("E connect.*Connection refused"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "socat: connection refused"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "socat: connection refused")
 		},
 	},
 
@@ -256,17 +261,19 @@ var SSTMap = types.RegexMap{
 		Verbosity: types.Detailed,
 	},
 
+	// only written by the xtrabackup SST script of pxc
 	"RegexTimeoutReceivingFirstData": &types.LogRegex{
 		Regex: regexp.MustCompile("Possible timeout in receving first data from donor in gtid/keyring stage"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "timeout from donor in gtid/keyring stage"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "timeout from donor in gtid/keyring stage")
 		},
+		Flavor: FlavorPXC,
 	},
 
 	"RegexWillNeverReceive": &types.LogRegex{
 		Regex: regexp.MustCompile("Will never receive state. Need to abort"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "will never receive SST, aborting"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "will never receive SST, aborting")
 		},
 	},
 
@@ -278,9 +285,7 @@ var SSTMap = types.RegexMap{
 			node := submatches[groupNodeIP]
 			istError := submatches["error"]
 
-			return ctx, func(ctx types.LogCtx) string {
-				return "IST to " + types.DisplayNodeSimplestForm(ctx, node) + utils.Paint(utils.RedText, " failed: ") + istError
-			}
+			return ctx, types.SegmentsDisplayer(types.Plain("IST to "), types.Node(node), types.Painted(utils.RedText, " failed: "), types.Plain(istError))
 		},
 	},
 }
@@ -9,17 +9,30 @@ import (
 
 func init() {
 	setType(types.StatesRegexType, StatesMap)
+
+	types.RegisterDisplayer("RegexShift", renderShift)
+	types.RegisterDisplayer("RegexRestoredState", func(ctx types.LogCtx, params map[string]string) string {
+		return "(restored)" + renderShift(ctx, params)
+	})
+}
+
+func renderShift(_ types.LogCtx, params map[string]string) string {
+	return utils.PaintForState(params["state1"], params["state1"]) + " -> " + utils.PaintForState(params["state2"], params["state2"])
 }
 
 var (
 	shiftFunc = func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 
 		ctx.SetState(submatches["state2"])
-		log = utils.PaintForState(submatches["state1"], submatches["state1"]) + " -> " + utils.PaintForState(submatches["state2"], submatches["state2"])
+		// TO is 0 until the node knows its position, it should not replace the one known from before a restart
+		if submatches[groupSeqno] != "0" {
+			ctx.SetPosition("", submatches[groupSeqno])
+		}
 
-		return ctx, types.SimpleDisplayer(log)
+		return ctx, types.NewDisplayer("RegexShift", map[string]string{"state1": submatches["state1"], "state2": submatches["state2"]})
 	}
-	shiftRegex = regexp.MustCompile("(?P<state1>[A-Z]+) -> (?P<state2>[A-Z]+)")
+	// the seqno is either "(TO: 123)" when shifting, or "(123)" for restored states
+	shiftRegex = regexp.MustCompile("(?P<state1>[A-Z]+) -> (?P<state2>[A-Z]+)(/[A-Z]+)?( \\((TO: )?(?P<" + groupSeqno + ">-?[0-9]+)\\))?")
 )
 
 var StatesMap = types.RegexMap{
@@ -37,7 +50,7 @@ var StatesMap = types.RegexMap{
 			var displayer types.LogDisplayer
 			ctx, displayer = shiftFunc(submatches, ctx, log)
 
-			return ctx, types.SimpleDisplayer("(restored)" + displayer(ctx))
+			return ctx, types.NewDisplayer("RegexRestoredState", displayer.Params)
 		},
 	},
 	// 2022-09-22T20:01:32.505660Z 0 [Note] [MY-000000] [Galera] Restored state OPEN -> SYNCED (13361114)
@@ -25,7 +25,7 @@ var ViewsMap = types.RegexMap{
 			if utils.SliceContains(ctx.OwnIPs, ip) {
 				return ctx, nil
 			}
-			return ctx, func(ctx types.LogCtx) string { return types.DisplayNodeSimplestForm(ctx, ip) + " established" }
+			return ctx, types.NodeDisplayer("", ip, " established")
 		},
 		Verbosity: types.DebugMySQL,
 	},
@@ -38,9 +38,7 @@ var ViewsMap = types.RegexMap{
 			ip := submatches[groupNodeIP]
 			ctx.HashToIP[submatches[groupNodeHash]] = ip
 			ctx.IPToMethod[ip] = submatches[groupMethod]
-			return ctx, func(ctx types.LogCtx) string {
-				return types.DisplayNodeSimplestForm(ctx, ip) + utils.Paint(utils.GreenText, " joined")
-			}
+			return ctx, types.SegmentsDisplayer(types.Node(ip), types.Painted(utils.GreenText, " joined"))
 		},
 	},
 
@@ -50,9 +48,7 @@ var ViewsMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 
 			ip := submatches[groupNodeIP]
-			return ctx, func(ctx types.LogCtx) string {
-				return types.DisplayNodeSimplestForm(ctx, ip) + utils.Paint(utils.RedText, " left")
-			}
+			return ctx, types.SegmentsDisplayer(types.Node(ip), types.Painted(utils.RedText, " left"))
 		},
 	},
 
@@ -77,15 +73,15 @@ var ViewsMap = types.RegexMap{
 				if !ctx.IsPrimary() {
 					ctx.SetState("PRIMARY")
 				}
-				msg := utils.Paint(utils.GreenText, "PRIMARY") + "(n=" + membNum + ")"
+				msg := "(n=" + membNum + ")"
 				if bootstrap {
 					msg += ",bootstrap"
 				}
-				return ctx, types.SimpleDisplayer(msg)
+				return ctx, types.SegmentsDisplayer(types.Painted(utils.GreenText, "PRIMARY"), types.Plain(msg))
 			}
 
 			ctx.SetState("NON-PRIMARY")
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "NON-PRIMARY") + "(n=" + membNum + ")")
+			return ctx, types.SegmentsDisplayer(types.Painted(utils.RedText, "NON-PRIMARY"), types.Plain("(n="+membNum+")"))
 		},
 	},
 
@@ -97,11 +93,9 @@ var ViewsMap = types.RegexMap{
 			hash := submatches[groupNodeHash]
 			ip, ok := ctx.HashToIP[hash]
 			if ok {
-				return ctx, func(ctx types.LogCtx) string {
-					return types.DisplayNodeSimplestForm(ctx, ip) + utils.Paint(utils.YellowText, " suspected to be down")
-				}
+				return ctx, types.SegmentsDisplayer(types.Node(ip), types.Painted(utils.YellowText, " suspected to be down"))
 			}
-			return ctx, types.SimpleDisplayer(hash + utils.Paint(utils.YellowText, " suspected to be down"))
+			return ctx, types.SegmentsDisplayer(types.Plain(hash), types.Painted(utils.YellowText, " suspected to be down"))
 		},
 		Verbosity: types.Detailed,
 	},
@@ -119,14 +113,12 @@ var ViewsMap = types.RegexMap{
 
 				// there could have additional corner case to discover yet
 				if !ok {
-					return ctx, types.SimpleDisplayer(hash + utils.Paint(utils.YellowText, " changed identity"))
+					return ctx, types.SegmentsDisplayer(types.Plain(hash), types.Painted(utils.YellowText, " changed identity"))
 				}
 				hash2 = utils.UUIDToShortUUID(hash2)
 			}
 			ctx.HashToIP[hash2] = ip
-			return ctx, func(ctx types.LogCtx) string {
-				return types.DisplayNodeSimplestForm(ctx, ip) + utils.Paint(utils.YellowText, " changed identity")
-			}
+			return ctx, types.SegmentsDisplayer(types.Node(ip), types.Painted(utils.YellowText, " changed identity"))
 		},
 		Verbosity: types.Detailed,
 	},
@@ -136,7 +128,7 @@ var ViewsMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("CLOSED")
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "not safe to bootstrap"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "not safe to bootstrap")
 		},
 	},
 	"RegexWsrepConsistenctyCompromised": &types.LogRegex{
@@ -144,44 +136,73 @@ var ViewsMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("CLOSED")
 
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "consistency compromised"))
+			return ctx, types.PaintedDisplayer(utils.RedText, "consistency compromised")
 		},
 	},
 	"RegexWsrepNonPrimary": &types.LogRegex{
 		Regex: regexp.MustCompile("failed to reach primary view"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			return ctx, types.SimpleDisplayer("received " + utils.Paint(utils.RedText, "non primary"))
+			return ctx, types.SegmentsDisplayer(types.Plain("received "), types.Painted(utils.RedText, "non primary"))
 		},
 	},
 
 	"RegexBootstrap": &types.LogRegex{
 		Regex: regexp.MustCompile("gcomm: bootstrapping new group"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "bootstrapping"))
+			return ctx, types.PaintedDisplayer(utils.YellowText, "bootstrapping")
 		},
 	},
 
 	"RegexSafeToBoostrapSet": &types.LogRegex{
 		Regex: regexp.MustCompile("safe_to_bootstrap: 1"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "safe_to_bootstrap: 1"))
+			return ctx, types.PaintedDisplayer(utils.YellowText, "safe_to_bootstrap: 1")
 		},
 	},
 	"RegexNoGrastate": &types.LogRegex{
 		Regex: regexp.MustCompile("Could not open state file for reading.*grastate.dat"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "no grastate.dat file"))
+			return ctx, types.PaintedDisplayer(utils.YellowText, "no grastate.dat file")
 		},
 		Verbosity: types.Detailed,
 	},
+	// 2023-01-06T07:05:35.698869Z 7 [Note] WSREP: New cluster view: global state: 8c9b5610-e020-11ed-a5ea-e253cc5f629d:20, view# 10: Primary, number of nodes: 2, my index: 0, protocol version 3
+	"RegexNewClusterView": &types.LogRegex{
+		Regex:         regexp.MustCompile("New cluster view: global state"),
+		InternalRegex: regexp.MustCompile("global state: " + regexUUID + ":(?P<" + groupSeqno + ">-?[0-9]+)"),
+		Handler:       viewPositionHandler,
+		Verbosity:     types.DebugMySQL,
+	},
+
+	// from 8.0, views are logged on multiple lines, without dates:
+	// View:
+	//   id: 8c9b5610-e020-11ed-a5ea-e253cc5f629d:20
+	//   status: primary
+	"RegexViewPosition": &types.LogRegex{
+		Regex:         regexp.MustCompile("^\\s*id: [a-z0-9]{8}-[a-z0-9]{4}-[a-z0-9]{4}-[a-z0-9]{4}-[a-z0-9]{12}:"),
+		InternalRegex: regexp.MustCompile("id: " + regexUUID + ":(?P<" + groupSeqno + ">-?[0-9]+)"),
+		Handler:       viewPositionHandler,
+		Verbosity:     types.DebugMySQL,
+	},
+
 	"RegexBootstrapingDefaultState": &types.LogRegex{
 		Regex: regexp.MustCompile("Bootstraping with default state"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "bootstrapping(empty grastate)"))
+			return ctx, types.PaintedDisplayer(utils.YellowText, "bootstrapping(empty grastate)")
 		},
 	},
 }
 
+// viewPositionHandler records the position of the cluster when the view was installed
+// non-primary views have an undefined position, it is ignored
+func viewPositionHandler(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+	ctx.SetPosition(submatches[groupUUID], submatches[groupSeqno])
+	if submatches[groupSeqno] == "-1" {
+		return ctx, nil
+	}
+	return ctx, types.SimpleDisplayer("view position(seqno:" + submatches[groupSeqno] + ")")
+}
+
 /*
 
 2022-11-29T23:34:51.820009-05:00 0 [Warning] [MY-000000] [Galera] Could not find peer: c0ff4085-5ad7-11ed-8b74-cfeec74147fe
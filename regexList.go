@@ -20,6 +20,9 @@ func (l *regexList) Run() error {
 
 	allregexes := regex.AllRegexes()
 	allregexes.Merge(regex.PXCOperatorMap)
+	if err := addCustomRules(allregexes); err != nil {
+		return err
+	}
 
 	if l.Json {
 		out, err := json.Marshal(&allregexes)
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// filterRegexes removes regexes according to --include-regexes, --include-types, --exclude-types and --exclude-regexes
+// This is done before compiling the grep argument, so that grep does not search for lines that would be thrown away
+// Identity regexes are kept by inclusions and type exclusions: nodes could not be identified without them.
+// They can still be removed one by one with --exclude-regexes
+func filterRegexes(regexes types.RegexMap) error {
+	for _, t := range append(CLI.IncludeTypes, CLI.ExcludeTypes...) {
+		if !utils.SliceContains(regexTypes, t) {
+			return errors.Errorf("unknown regex type %s, should be one of: %s", t, strings.Join(regexTypes, ", "))
+		}
+	}
+	if utils.SliceContains(CLI.ExcludeTypes, string(types.IdentRegexType)) {
+		logger.Warn().Msg("identity regexes are needed to identify nodes, they will not be excluded")
+	}
+
+	for key, r := range regexes {
+		keep, err := keepRegex(key, r)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			delete(regexes, key)
+		}
+	}
+	return nil
+}
+
+var regexTypes = []string{
+	string(types.EventsRegexType),
+	string(types.SSTRegexType),
+	string(types.ViewsRegexType),
+	string(types.IdentRegexType),
+	string(types.StatesRegexType),
+	string(types.PXCOperatorRegexType),
+	string(types.ApplicativeRegexType),
+}
+
+func keepRegex(key string, r *types.LogRegex) (bool, error) {
+	excluded, err := matchRegexKeys(CLI.ExcludeRegexes, key)
+	if err != nil || excluded {
+		return false, err
+	}
+	if r.Type == types.IdentRegexType {
+		return true, nil
+	}
+	if utils.SliceContains(CLI.ExcludeTypes, string(r.Type)) {
+		return false, nil
+	}
+	if len(CLI.IncludeTypes) > 0 && !utils.SliceContains(CLI.IncludeTypes, string(r.Type)) {
+		return false, nil
+	}
+	if len(CLI.IncludeRegexes) > 0 {
+		return matchRegexKeys(CLI.IncludeRegexes, key)
+	}
+	return true, nil
+}
+
+// matchRegexKeys accepts exact keys, globs (RegexSST*), or regexes between slashes (/^Regex(SST|IST)/)
+func matchRegexKeys(patterns []string, key string) (bool, error) {
+	for _, pattern := range patterns {
+		if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+			if err != nil {
+				return false, errors.Wrapf(err, "invalid regex key pattern %s", pattern)
+			}
+			if re.MatchString(key) {
+				return true, nil
+			}
+			continue
+		}
+		matched, err := path.Match(pattern, key)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid regex key pattern %s", pattern)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
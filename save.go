@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+type save struct {
+	Paths    []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Output   string   `short:"o" required:"" help:"File to write the snapshot to, - for stdout"`
+	KeepLogs bool     `help:"Also save raw log lines. They are removed by default, so that snapshots can be shared without sharing logs"`
+}
+
+func (s *save) Help() string {
+	return `Save the timeline of events to a file, to render it later with 'list --from-snapshot'
+Every event is saved, list flags (--sst, --views, ...) select what to show when rendering
+
+Usage:
+	galera-log-explainer save -o snapshot.json *.log
+	galera-log-explainer list --all --from-snapshot snapshot.json
+`
+}
+
+// snapshotFormat is increased whenever older versions would not be able to read snapshots
+const snapshotFormat = 1
+
+type snapshot struct {
+	Format      int            `json:"format"`
+	Version     string         `json:"version"`
+	LogsRemoved bool           `json:"logsRemoved"`
+	Timeline    types.Timeline `json:"timeline"`
+}
+
+func (s *save) Run() error {
+
+	timeline, err := timelineFromPaths(s.Paths, regex.AllRegexes())
+	if err != nil {
+		return errors.Wrap(err, "Could not save")
+	}
+
+	if !s.KeepLogs {
+		for _, lt := range timeline {
+			for i := range lt {
+				lt[i].Log = ""
+			}
+		}
+	}
+
+	return writeSnapshot(s.Output, timeline, !s.KeepLogs)
+}
+
+// writeSnapshot writes to stdout when output is -
+func writeSnapshot(output string, timeline types.Timeline, logsRemoved bool) error {
+	var w io.Writer = os.Stdout
+	if output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return errors.Wrap(err, "failed to create snapshot")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	err := json.NewEncoder(w).Encode(snapshot{Format: snapshotFormat, Version: version, LogsRemoved: logsRemoved, Timeline: timeline})
+	return errors.Wrap(err, "failed to write snapshot")
+}
+
+func loadSnapshot(path string) (snapshot, error) {
+	snap := snapshot{}
+	f, err := os.Open(path)
+	if err != nil {
+		return snap, errors.Wrap(err, "failed to open snapshot")
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&snap); err != nil {
+		return snap, errors.Wrapf(err, "failed to read snapshot %s", path)
+	}
+	if snap.Format > snapshotFormat {
+		return snap, errors.Errorf("snapshot %s was saved by a newer version (%s), it can't be read", path, snap.Version)
+	}
+	if len(snap.Timeline) == 0 {
+		return snap, errors.Errorf("snapshot %s has no events", path)
+	}
+	return snap, nil
+}
+
+// selectFromSnapshot keeps the events list would have searched for, with the same verbosities
+// events from rules that are not known here, such as custom rules, are only filtered by --include/--exclude options
+func selectFromSnapshot(timeline types.Timeline, regexes types.RegexMap) (types.Timeline, error) {
+	selected := make(types.Timeline, len(timeline))
+	for node, lt := range timeline {
+		newlt := types.LocalTimeline{}
+		for _, li := range lt {
+			if r, ok := regexes[li.RegexUsed]; ok {
+				li.Verbosity = r.Verbosity
+				newlt = append(newlt, li)
+				continue
+			}
+			if isBuiltinRegex(li.RegexUsed) {
+				continue
+			}
+			keep, err := keepRegex(li.RegexUsed, &types.LogRegex{Type: li.RegexType})
+			if err != nil {
+				return nil, err
+			}
+			if keep {
+				newlt = append(newlt, li)
+			}
+		}
+		if len(newlt) > 0 {
+			selected[node] = newlt
+		}
+	}
+	return selected, nil
+}
+
+func isBuiltinRegex(key string) bool {
+	for _, m := range []types.RegexMap{regex.IdentsMap, regex.ViewsMap, regex.SSTMap, regex.EventsMap, regex.StatesMap, regex.ApplicativeMap, regex.PXCOperatorMap} {
+		if _, ok := m[key]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/ylacancellera/galera-log-explainer/regex"
 	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
 )
 
 type sed struct {
@@ -28,6 +29,9 @@ You can also simply call the command to get a generated sed command to review an
 }
 
 func (s *sed) Run() error {
+	if utils.SliceContains(s.Paths, "-") {
+		return errors.New("sed subcommand already reads the log to translate from stdin, '-' can't be used as a path")
+	}
 	toCheck := regex.AllRegexes()
 	timeline, err := timelineFromPaths(s.Paths, toCheck)
 	if err != nil {
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+)
+
+// vars rather than consts so tests can use small files
+var (
+	// smaller files are fast enough to read entirely
+	seekMinSize int64 = 64 * 1024 * 1024
+
+	// the binary search stops once the range is this small
+	seekPrecision int64 = 64 * 1024
+
+	// error logs are mostly, not strictly, ordered: a bit more is read around the range found
+	seekMargin int64 = 1024 * 1024
+
+	// how far to look for a dated line from a given offset
+	seekMaxScan int64 = 1024 * 1024
+
+	// dates without offsets may be in any timezone when --tz is used
+	seekZoneMargin = 14 * time.Hour
+)
+
+// seekSource restricts a large regular file to the byte range that can hold dates between --since and --until
+// Lines without dates right after --since are still sent, they are then handled the same way as when reading from the start
+// Any other source is returned as-is
+func seekSource(source logSource) logSource {
+	if !source.regular || (CLI.Since == nil && CLI.Until == nil) {
+		return source
+	}
+	st, err := os.Stat(source.file)
+	if err != nil || st.Size() < seekMinSize {
+		return source
+	}
+
+	start, end, err := seekRange(source.file, st.Size(), CLI.Since, CLI.Until)
+	if err != nil {
+		logger.Warn().Str("path", source.path).Err(err).Msg("failed to seek, reading the whole file")
+		return source
+	}
+	logger.Debug().Str("path", source.path).Int64("start", start).Int64("end", end).Int64("size", st.Size()).Msg("seeked")
+	if start == 0 && end == st.Size() {
+		return source
+	}
+
+	file := source.file
+	source.regular = false
+	source.open = func() (io.ReadCloser, error) {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		return &readCloser{Reader: io.NewSectionReader(f, start, end-start), closers: []func() error{f.Close}}, nil
+	}
+	return source
+}
+
+// seekRange binary searches the file by byte offsets, using the first dated line found after each offset
+func seekRange(file string, size int64, since, until *time.Time) (start, end int64, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to open %s", file)
+	}
+	defer f.Close()
+
+	start, end = 0, size
+	if since != nil {
+		// when no dates could be found, it is safer to start earlier
+		lo := searchOffset(f, size, func(t time.Time, zoned, found bool) bool {
+			if !zoned {
+				t = t.Add(seekZoneMargin)
+			}
+			return found && t.Before(*since)
+		})
+		start, err = lineStart(f, lo-seekMargin)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if until != nil {
+		// and to stop later
+		hi := searchOffset(f, size, func(t time.Time, zoned, found bool) bool {
+			if !zoned {
+				t = t.Add(-seekZoneMargin)
+			}
+			return !found || !t.After(*until)
+		})
+		// lines without dates after the last one before --until belong to it, the range ends at the next dated line
+		end = size
+		if _, _, next, found := dateAfter(f, hi+seekPrecision); found {
+			end, err = lineStart(f, next+seekMargin)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	if end > size {
+		end = size
+	}
+	if end < start {
+		end = start
+	}
+	return start, end, nil
+}
+
+// searchOffset returns the last offset where before is still true, to the nearest seekPrecision
+// zoned is false when the date is in local time and --tz was used, it can be off by a few hours
+// found is false when there were no dated lines after the offset
+func searchOffset(f *os.File, size int64, before func(t time.Time, zoned, found bool) bool) int64 {
+	lo, hi := int64(0), size
+	for hi-lo > seekPrecision {
+		mid := lo + (hi-lo)/2
+		t, layout, _, found := dateAfter(f, mid)
+		if before(t, regex.DateLayoutHasZone(layout) || len(tz.rules) == 0, found) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// dateAfter returns the date and offset of the first dated line starting after offset
+func dateAfter(f *os.File, offset int64) (time.Time, string, int64, bool) {
+	start, err := lineStart(f, offset)
+	if err != nil {
+		return time.Time{}, "", 0, false
+	}
+	r := bufio.NewReader(io.NewSectionReader(f, start, seekMaxScan))
+	for {
+		line, err := r.ReadString('\n')
+		if t, layout, ok := regex.SearchDateFromLog(line); ok {
+			return t, layout, start, true
+		}
+		if err != nil {
+			return time.Time{}, "", 0, false
+		}
+		start += int64(len(line))
+	}
+}
+
+// lineStart returns the offset of the first line starting at or after offset
+func lineStart(f *os.File, offset int64) (int64, error) {
+	if offset <= 0 {
+		return 0, nil
+	}
+	r := bufio.NewReader(io.NewSectionReader(f, offset-1, 1<<62))
+	skipped, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, errors.Wrap(err, "failed to find the start of a line")
+	}
+	return offset - 1 + int64(len(skipped)), nil
+}
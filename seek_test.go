@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	seekTestLineLen       = 128
+	seekTestContinuations = 40
+)
+
+var seekTestBase = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type seekTestLine struct {
+	offset int64
+	date   time.Time // zero for continuation lines
+	text   string
+}
+
+// writeSeekTestFile writes one dated line per second, every 100th one being followed by undated lines
+// lines all have the same length, so that some of them start right at a seekPrecision boundary
+func writeSeekTestFile(t *testing.T, dated, continuations int) (string, []seekTestLine) {
+	pad := func(s string) string {
+		return s + strings.Repeat(".", seekTestLineLen-1-len(s)) + "\n"
+	}
+
+	var (
+		b      strings.Builder
+		lines  []seekTestLine
+		offset int64
+	)
+	add := func(date time.Time, text string) {
+		lines = append(lines, seekTestLine{offset: offset, date: date, text: text})
+		b.WriteString(text)
+		offset += int64(len(text))
+	}
+	for i := 0; i < dated; i++ {
+		date := seekTestBase.Add(time.Duration(i) * time.Second)
+		add(date, pad(fmt.Sprintf("%s 0 [Note] [MY-000000] [Galera] event %d ", date.Format("2006-01-02T15:04:05.000000Z"), i)))
+		if i%100 == 0 {
+			for j := 0; j < continuations; j++ {
+				add(time.Time{}, pad(fmt.Sprintf("  continuation %d of event %d ", j, i)))
+			}
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "mysqld.log")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path, lines
+}
+
+// testSeek lowers the thresholds so that a few hundred KB are enough to exercise the binary search
+func testSeek(t *testing.T) {
+	testCLI(t)
+	savedMinSize, savedPrecision, savedMargin, savedMaxScan := seekMinSize, seekPrecision, seekMargin, seekMaxScan
+	t.Cleanup(func() {
+		seekMinSize, seekPrecision, seekMargin, seekMaxScan = savedMinSize, savedPrecision, savedMargin, savedMaxScan
+	})
+	seekMinSize = 1024
+	seekPrecision = 1024
+	seekMargin = 4 * 1024
+	seekMaxScan = 8 * 1024
+}
+
+func TestSeekRange(t *testing.T) {
+	testSeek(t)
+	path, lines := writeSeekTestFile(t, 5000, seekTestContinuations)
+	size := lines[len(lines)-1].offset + seekTestLineLen
+
+	// the first dated lines starting and ending right at a chunk boundary
+	var atEdge, beforeEdge seekTestLine
+	for _, l := range lines {
+		if l.date.IsZero() || l.offset < 10*seekPrecision {
+			continue
+		}
+		if atEdge.date.IsZero() && l.offset%seekPrecision == 0 {
+			atEdge = l
+		}
+		if beforeEdge.date.IsZero() && (l.offset+seekTestLineLen)%seekPrecision == 0 {
+			beforeEdge = l
+		}
+	}
+	if atEdge.date.IsZero() || beforeEdge.date.IsZero() {
+		t.Fatal("no line on a chunk boundary, the test file is wrong")
+	}
+
+	at := func(seconds int) *time.Time {
+		d := seekTestBase.Add(time.Duration(seconds) * time.Second)
+		return &d
+	}
+
+	tests := []struct {
+		name         string
+		since, until *time.Time
+		// continuations is the event whose undated lines must be sent
+		continuations int
+		// whether nothing at all should be in range
+		empty bool
+	}{
+		{name: "since only", since: at(2500), continuations: 2500},
+		{name: "until only", until: at(2500), continuations: 2500},
+		{name: "since and until", since: at(1000), until: at(1200), continuations: 1000},
+		{name: "since at a chunk edge", since: &atEdge.date, continuations: -1},
+		{name: "since right before a chunk edge", since: &beforeEdge.date, continuations: -1},
+		{name: "until at a chunk edge", until: &atEdge.date, continuations: -1},
+		{name: "until right before a chunk edge", until: &beforeEdge.date, continuations: -1},
+		{name: "continuations after since", since: at(3101), until: at(3300), continuations: 3200},
+		{name: "since before the file", since: at(-3600), until: at(10), continuations: 0},
+		{name: "until after the file", since: at(4900), until: at(10000), continuations: 4900},
+		{name: "since past the end of the file", since: at(10000), continuations: -1, empty: true},
+		{name: "until before the file", until: at(-3600), continuations: -1, empty: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end, err := seekRange(path, size, test.since, test.until)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if start < 0 || end > size || start > end {
+				t.Fatalf("invalid range %d-%d for a %d bytes file", start, end, size)
+			}
+			if start%seekTestLineLen != 0 || (end != size && end%seekTestLineLen != 0) {
+				t.Errorf("range %d-%d does not start and end on lines", start, end)
+			}
+
+			inRange := func(l seekTestLine) bool {
+				return l.offset >= start && l.offset+seekTestLineLen <= end
+			}
+			for _, l := range lines {
+				switch {
+				case l.date.IsZero():
+				case test.since != nil && l.date.Before(*test.since):
+				case test.until != nil && l.date.After(*test.until):
+				case !inRange(l):
+					t.Errorf("line at %d dated %s is missing from range %d-%d", l.offset, l.date, start, end)
+				}
+			}
+			if test.continuations >= 0 {
+				suffix := fmt.Sprintf(" of event %d ", test.continuations)
+				for _, l := range lines {
+					if strings.Contains(l.text, suffix) && !inRange(l) {
+						t.Errorf("continuation line at %d is missing from range %d-%d", l.offset, start, end)
+					}
+				}
+			}
+
+			// at most the margins and one block of undated lines are read around the limit
+			if test.empty && end-start > seekMargin+2*seekPrecision+seekTestContinuations*seekTestLineLen {
+				t.Errorf("expected a range near the limit, got %d-%d for a %d bytes file", start, end, size)
+			}
+			if !test.empty && end-start == size {
+				t.Errorf("the whole file was kept")
+			}
+		})
+	}
+}
+
+func TestSeekSource(t *testing.T) {
+	testSeek(t)
+	path, lines := writeSeekTestFile(t, 2000, 10)
+
+	since := seekTestBase.Add(1000 * time.Second)
+	CLI.Since = &since
+	source := seekSource(logSource{path: path, file: path, regular: true, open: func() (io.ReadCloser, error) { return os.Open(path) }})
+	if source.regular {
+		t.Fatal("the source should have been restricted to a range")
+	}
+
+	r, err := source.open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var full strings.Builder
+	for _, l := range lines {
+		full.WriteString(l.text)
+	}
+	if !strings.HasSuffix(full.String(), string(content)) {
+		t.Errorf("the range read is not the end of the file")
+	}
+	if !strings.Contains(string(content), " event 1000 ") {
+		t.Errorf("the line dated --since is missing")
+	}
+
+	// too small files are read entirely
+	seekMinSize = int64(full.Len()) + 1
+	source = seekSource(logSource{path: path, file: path, regular: true})
+	if !source.regular {
+		t.Errorf("files below seekMinSize should not be restricted")
+	}
+}
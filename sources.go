@@ -0,0 +1,302 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Compressions are detected using magic bytes instead of file extensions
+// support bundles are often renamed, or have their extensions stripped
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// logSource is anything we can extract events from: a regular file, a compressed file, or a member of an archive
+type logSource struct {
+	// path is what will be displayed as the log file path
+	// archive members are shown as "bundle.tar.gz:var/lib/mysql/mysqld-error.log"
+	path string
+
+	// file is the actual file on disk, it can differ from path when reading from stdin
+	// archive members are extracted to temporary files
+	file string
+
+	// archive is the archive members were extracted from, it identifies them better than their temporary file
+	archive string
+
+	// open can be called as many times as needed, each call will start reading from the beginning
+	open func() (io.ReadCloser, error)
+
+	// regular is true when the file can be read as-is, without decompression nor conversion
+	// it enables to give the file path directly to grep
+	regular bool
+}
+
+// readCloser chains the closers of every readers it has been built from
+type readCloser struct {
+	io.Reader
+	closers    []func() error
+	compressed bool
+	journald   bool
+}
+
+func (rc *readCloser) Close() error {
+	var err error
+	// closing in reverse order: decompressors first, the underlying file last
+	for i := len(rc.closers) - 1; i >= 0; i-- {
+		if cerr := rc.closers[i](); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// expandPaths transforms every path into sources to read
+// archives are expanded into one source per regular file they contain
+func expandPaths(paths []string) []logSource {
+	sources := []logSource{}
+	for _, path := range paths {
+		s, err := sourcesFromPath(path)
+		if err != nil {
+			logger.Error().Str("path", path).Err(err).Msg("failed to open log")
+			continue
+		}
+		sources = append(sources, s...)
+	}
+	return sources
+}
+
+func sourcesFromPath(path string) ([]logSource, error) {
+
+	file := path
+	if path == "-" {
+		var err error
+		file, err = spoolStdin()
+		if err != nil {
+			return nil, err
+		}
+		path = "stdin"
+	}
+
+	openFile := func() (io.ReadCloser, error) {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		return openLog(f, f.Close)
+	}
+
+	r, err := openFile()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s", path)
+	}
+	regular := !r.(*readCloser).compressed && !r.(*readCloser).journald
+	isArchive := isTar(r)
+	r.Close()
+
+	if !isArchive {
+		return []logSource{{path: path, file: file, open: openFile, regular: regular}}, nil
+	}
+
+	return archiveMembers(path, file, openFile)
+}
+
+// stdin can only be read once, while sources can be opened multiple times
+// it is copied to a temporary file, removed by cleanupTemporaryFiles
+var stdinFile string
+
+// temporaryFiles are removed before exiting
+var temporaryFiles []string
+
+func spoolStdin() (string, error) {
+	if stdinFile != "" {
+		return stdinFile, nil
+	}
+	f, err := os.CreateTemp("", "galera-log-explainer-stdin-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temporary file for stdin")
+	}
+	defer f.Close()
+	stdinFile = f.Name()
+	temporaryFiles = append(temporaryFiles, stdinFile)
+
+	_, err = io.Copy(f, os.Stdin)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read stdin")
+	}
+	return stdinFile, nil
+}
+
+func cleanupTemporaryFiles() {
+	for _, f := range temporaryFiles {
+		os.RemoveAll(f)
+	}
+}
+
+// isTar checks for the "ustar" magic, located at offset 257 of every tar header
+func isTar(r io.Reader) bool {
+	header := make([]byte, 262)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return false
+	}
+	return string(header[257:262]) == "ustar"
+}
+
+// archiveMembers extracts every log of the archive to a temporary directory, in a single pass
+// tar archives can't be seeked, especially when compressed: reading them once per member would be too slow
+// members are decompressed and converted while extracted, so that grep can read them directly
+func archiveMembers(path, file string, openArchive func() (io.ReadCloser, error)) ([]logSource, error) {
+	r, err := openArchive()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s", path)
+	}
+	defer r.Close()
+
+	dir, err := os.MkdirTemp("", "galera-log-explainer-archive-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temporary directory for archive")
+	}
+	temporaryFiles = append(temporaryFiles, dir)
+
+	sources := []logSource{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list members of %s", path)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		member := path + ":" + hdr.Name
+		extracted := filepath.Join(dir, strconv.Itoa(len(sources)))
+		isLog, err := extractArchiveMember(tr, extracted)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to extract %s", member)
+		}
+		if !isLog {
+			logger.Debug().Str("path", member).Msg("not a log, skipped")
+			os.Remove(extracted)
+			continue
+		}
+		sources = append(sources, logSource{
+			path:    member,
+			file:    extracted,
+			archive: file,
+			open: func() (io.ReadCloser, error) {
+				f, err := os.Open(extracted)
+				if err != nil {
+					return nil, err
+				}
+				return &readCloser{Reader: f, closers: []func() error{f.Close}}, nil
+			},
+			regular: true,
+		})
+	}
+	return sources, nil
+}
+
+// binarySniffSize is how much is read to tell whether a member is a text log, as git does
+const binarySniffSize = 8000
+
+// extractArchiveMember writes the member decompressed and converted to output
+// binaries such as datafiles and libraries are not logs, they are not written
+func extractArchiveMember(member io.Reader, output string) (bool, error) {
+	// members can be compressed themselves, as with rotated logs
+	rc, err := openLog(member, func() error { return nil })
+	if err != nil {
+		return false, err
+	}
+	defer rc.Close()
+
+	br := bufio.NewReaderSize(rc, binarySniffSize)
+	head, _ := br.Peek(binarySniffSize)
+	if bytes.IndexByte(head, 0) >= 0 {
+		return false, nil
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return false, err
+	}
+	_, err = io.Copy(f, br)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return true, err
+}
+
+// openLog returns a reader of the log lines, decompressed and converted if needed
+func openLog(r io.Reader, closer func() error) (io.ReadCloser, error) {
+	rc, err := decompress(r, closer)
+	if err != nil {
+		return nil, err
+	}
+	convertJournald(rc)
+	return rc, nil
+}
+
+// decompress detects the compression of a reader and returns a reader of the decompressed content
+// When no compression is detected, the content is returned as-is
+func decompress(r io.Reader, closer func() error) (*readCloser, error) {
+	br := bufio.NewReader(r)
+	rc := &readCloser{Reader: br, closers: []func() error{closer}}
+
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			rc.Close()
+			return nil, errors.Wrap(err, "failed to read gzip")
+		}
+		rc.Reader = gz
+		rc.closers = append(rc.closers, gz.Close)
+		rc.compressed = true
+
+	case bytes.HasPrefix(magic, bzip2Magic):
+		rc.Reader = bzip2.NewReader(br)
+		rc.compressed = true
+
+	case bytes.HasPrefix(magic, zstdMagic):
+		// there are no zstd decompressor in the standard library, the zstd binary is used instead
+		// the same way "grep" is
+		cmd := exec.Command("zstd", "-dc")
+		cmd.Stdin = br
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			rc.Close()
+			return nil, errors.Wrap(err, "failed to read zstd")
+		}
+		if err = cmd.Start(); err != nil {
+			rc.Close()
+			return nil, errors.Wrap(err, "failed to start zstd, is it installed?")
+		}
+		rc.Reader = out
+		rc.closers = append(rc.closers, func() error {
+			out.Close()
+			// it will be killed when closed before the end
+			cmd.Wait()
+			return nil
+		})
+		rc.compressed = true
+	}
+	return rc, nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+const sourcesTestLog = "testdata/db1.example.com/172.17.0.2.log"
+
+func gzipped(t *testing.T, content []byte) []byte {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func tarred(t *testing.T, members map[string][]byte) []byte {
+	var b bytes.Buffer
+	w := tar.NewWriter(&b)
+	for _, name := range []string{"var/log/mysqld.log", "var/log/mysqld.log.1.gz", "var/lib/mysql/ibdata1"} {
+		content, ok := members[name]
+		if !ok {
+			continue
+		}
+		if err := w.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func readSource(t *testing.T, source logSource) string {
+	r, err := source.open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// compressions and archives are detected from their content, the extensions are misleading on purpose
+func TestSourcesFromPath(t *testing.T) {
+	testCLI(t)
+	savedTemporaryFiles := temporaryFiles
+	t.Cleanup(func() {
+		cleanupTemporaryFiles()
+		temporaryFiles = savedTemporaryFiles
+	})
+	temporaryFiles = nil
+
+	log, err := os.ReadFile(sourcesTestLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	write := func(name string, content []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	archive := tarred(t, map[string][]byte{
+		"var/log/mysqld.log":      log,
+		"var/log/mysqld.log.1.gz": gzipped(t, log),
+		"var/lib/mysql/ibdata1":   {0, 0, 0, 1, 0, 2},
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		zstd    bool
+		regular bool
+		// members are the expected paths of the sources, the path itself when it is not an archive
+		members []string
+	}{
+		{name: "plain", path: sourcesTestLog, regular: true},
+		{name: "gzip", path: write("gzip.log", gzipped(t, log))},
+		{name: "bzip2", path: "testdata/compressed/bzip2.log"},
+		{name: "zstd", path: "testdata/compressed/zstd.log", zstd: true},
+		{name: "tar", path: write("bundle.log", archive), regular: true, members: []string{"var/log/mysqld.log", "var/log/mysqld.log.1.gz"}},
+		{name: "tar.gz", path: write("bundle.tar", gzipped(t, archive)), regular: true, members: []string{"var/log/mysqld.log", "var/log/mysqld.log.1.gz"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.zstd {
+				if _, err := exec.LookPath("zstd"); err != nil {
+					t.Skip("zstd is not installed")
+				}
+			}
+			sources, err := sourcesFromPath(test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := []string{test.path}
+			if test.members != nil {
+				expected = nil
+				for _, m := range test.members {
+					expected = append(expected, test.path+":"+m)
+				}
+			}
+			if len(sources) != len(expected) {
+				t.Fatalf("expected %d sources, got %d: %v", len(expected), len(sources), sources)
+			}
+			for i, source := range sources {
+				if source.path != expected[i] {
+					t.Errorf("expected source %s, got %s", expected[i], source.path)
+				}
+				if source.regular != test.regular {
+					t.Errorf("expected regular=%t for %s", test.regular, source.path)
+				}
+				if content := readSource(t, source); content != string(log) {
+					t.Errorf("unexpected content for %s: %q", source.path, content)
+				}
+				// sources can be opened again
+				if content := readSource(t, source); content != string(log) {
+					t.Errorf("unexpected content when reopening %s", source.path)
+				}
+			}
+		})
+	}
+}
+
+func TestSourcesFromStdin(t *testing.T) {
+	testCLI(t)
+	savedStdin, savedStdinFile, savedTemporaryFiles := os.Stdin, stdinFile, temporaryFiles
+	t.Cleanup(func() {
+		cleanupTemporaryFiles()
+		os.Stdin, stdinFile, temporaryFiles = savedStdin, savedStdinFile, savedTemporaryFiles
+	})
+	stdinFile, temporaryFiles = "", nil
+
+	log, err := os.ReadFile(sourcesTestLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stdin is compressed as well, as with "kubectl cp" or "ssh cat" of a rotated log
+	compressed := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(compressed, gzipped(t, log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	os.Stdin = f
+
+	sources, err := sourcesFromPath("-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 1 || sources[0].path != "stdin" || sources[0].regular {
+		t.Fatalf("unexpected sources: %+v", sources)
+	}
+	if content := readSource(t, sources[0]); content != string(log) {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	// stdin was spooled once, it can be listed again
+	again, err := sourcesFromPath("-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again[0].file != sources[0].file {
+		t.Errorf("stdin was read twice")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/analysis"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+type sst struct {
+	Paths []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Json  bool     `help:"Print the state transfers as json"`
+}
+
+func (s *sst) Help() string {
+	return `List every state transfer (SST and IST), from the request to its completion or failure, with durations
+
+Usage:
+	galera-log-explainer sst *.log
+	galera-log-explainer sst --json *.log
+
+Transfers are correlated between every log given: the joiner and donor logs tell the type, method and failures
+Durations are from the request to the end, they are missing when one of them is not in the logs`
+}
+
+func (s *sst) Run() error {
+
+	timeline, err := timelineFromPaths(s.Paths, regex.AllRegexes())
+	if err != nil {
+		return errors.Wrap(err, "Could not list state transfers")
+	}
+
+	transfers := analysis.Transfers(timeline)
+
+	if s.Json {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "\t")
+		return e.Encode(transfers)
+	}
+
+	if len(transfers) == 0 {
+		fmt.Println("no state transfer found")
+		return nil
+	}
+
+	formatDate := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(reportDateLayout)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 8, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "start\tend\tduration\ttype\tmethod\tjoiner\tdonor\toutcome\t")
+	for _, t := range transfers {
+		duration := ""
+		if t.Duration > 0 {
+			duration = t.Duration.Round(time.Second).String()
+		}
+		outcome := string(t.Outcome)
+		switch t.Outcome {
+		case analysis.TransferSuccess:
+			outcome = utils.Paint(utils.GreenText, outcome)
+		case analysis.TransferFailed:
+			outcome = utils.Paint(utils.RedText, outcome)
+			if t.Error != "" {
+				outcome += ": " + t.Error
+			}
+		case analysis.TransferUnfinished:
+			outcome = utils.Paint(utils.YellowText, outcome)
+		}
+		fmt.Fprintln(w, strings.Join([]string{formatDate(t.Start), formatDate(t.End), duration, t.Type, t.Method, t.Joiner, t.Donor, outcome}, "\t")+"\t")
+	}
+	return w.Flush()
+}
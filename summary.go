@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/analysis"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+type summary struct {
+	Paths []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Json  bool     `help:"Print the summary as json"`
+}
+
+func (s *summary) Help() string {
+	return `Summarize what happened across the cluster, in chronological order
+It lists crashes, primary component losses, state transfers and inconsistency votes
+
+Usage:
+	galera-log-explainer summary *.log
+	galera-log-explainer summary --json *.log
+`
+}
+
+// reportDateLayout is used by commands reporting on the whole cluster, where dates from many nodes/layouts are mixed
+const reportDateLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+var summaryColors = map[analysis.SummaryKind]utils.Color{
+	analysis.SummaryCrash:           utils.RedText,
+	analysis.SummaryPrimaryLost:     utils.RedText,
+	analysis.SummaryPrimaryRegained: utils.GreenText,
+	analysis.SummaryStateTransfer:   utils.YellowText,
+	analysis.SummaryTransferFailed:  utils.RedText,
+	analysis.SummaryConflict:        utils.RedText,
+}
+
+func (s *summary) Run() error {
+
+	timeline, err := timelineFromPaths(s.Paths, regex.AllRegexes())
+	if err != nil {
+		return errors.Wrap(err, "Could not summarize")
+	}
+
+	events := analysis.Summarize(timeline)
+
+	if s.Json {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "\t")
+		return e.Encode(events)
+	}
+
+	if len(events) == 0 {
+		fmt.Println("Nothing notable found")
+		return nil
+	}
+	for _, event := range events {
+		fmt.Println(event.Date.Format(reportDateLayout) + "  " + utils.Paint(summaryColors[event.Kind], event.Text))
+	}
+	return nil
+}
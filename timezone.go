@@ -0,0 +1,125 @@
+package main
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+// timezones holds what was given through --tz and --display-tz
+// Only dates written without offsets are affected by --tz, 5.7+ logs already have them
+type timezones struct {
+	rules []tzRule
+
+	// display is the zone every date is converted to. nil keeps dates the way they were written
+	display *time.Location
+}
+
+// tzRule is a single --tz entry. An empty pattern is the default zone for every file
+type tzRule struct {
+	pattern string
+	loc     *time.Location
+}
+
+var tz = &timezones{}
+
+// newTimezones reads --tz entries: "Europe/Paris", "node1=Europe/Paris" or "*/eu-*/*.log=Europe/Paris"
+// As soon as a zone is given, dates are displayed in UTC unless displayZone is set
+func newTimezones(entries []string, displayZone string) (*timezones, error) {
+	t := &timezones{}
+	for _, entry := range entries {
+		pattern, zone := "", entry
+		if i := strings.LastIndex(entry, "="); i >= 0 {
+			pattern, zone = entry[:i], entry[i+1:]
+		}
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid timezone in --tz=%s", entry)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern in --tz=%s", entry)
+		}
+		t.rules = append(t.rules, tzRule{pattern: pattern, loc: loc})
+	}
+
+	if displayZone != "" {
+		loc, err := time.LoadLocation(displayZone)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid timezone in --display-tz=%s", displayZone)
+		}
+		t.display = loc
+	} else if len(t.rules) > 0 {
+		t.display = time.UTC
+	}
+	return t, nil
+}
+
+// forPath returns the zone of a file when a pattern matches its path or its base name
+func (t *timezones) forPath(p string) (*time.Location, bool) {
+	for _, rule := range t.rules {
+		if rule.pattern == "" {
+			continue
+		}
+		full, _ := path.Match(rule.pattern, p)
+		base, _ := path.Match(rule.pattern, path.Base(p))
+		if full || base {
+			return rule.loc, true
+		}
+	}
+	return nil, false
+}
+
+// forNode returns the zone of a node, using any of the names or IPs it was identified with
+func (t *timezones) forNode(ctx types.LogCtx) (*time.Location, bool) {
+	for _, rule := range t.rules {
+		if rule.pattern == "" {
+			continue
+		}
+		for _, name := range append(ctx.OwnNames, ctx.OwnIPs...) {
+			if matched, _ := path.Match(rule.pattern, name); matched {
+				return rule.loc, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// defaultZone is the zone given without patterns, nil if there are none
+func (t *timezones) defaultZone() *time.Location {
+	for _, rule := range t.rules {
+		if rule.pattern == "" {
+			return rule.loc
+		}
+	}
+	return nil
+}
+
+// date builds the date of a log line. Dates without offsets are read in loc, when it is set
+func (t *timezones) date(parsed time.Time, layout string, loc *time.Location) *types.Date {
+	if loc != nil && !regex.DateLayoutHasZone(layout) {
+		parsed = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), parsed.Hour(), parsed.Minute(), parsed.Second(), parsed.Nanosecond(), loc)
+	}
+	if t.display == nil {
+		return types.NewDate(parsed, layout)
+	}
+	return types.NewDateIn(parsed, layout, t.display)
+}
+
+// relocate reads again dates without offsets in another zone
+// it is needed when a zone is given by node name: names are only known once the node is identified
+func (t *timezones) relocate(lt []types.LogInfo, from, to *time.Location) {
+	if from == nil {
+		from = time.UTC
+	}
+	for i := range lt {
+		d := lt[i].Date
+		if d == nil || regex.DateLayoutHasZone(d.Layout) {
+			continue
+		}
+		lt[i].Date = t.date(d.Time.In(from), d.Layout, to)
+	}
+}
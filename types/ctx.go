@@ -2,6 +2,8 @@ package types
 
 import (
 	"encoding/json"
+	"strings"
+	"time"
 
 	"github.com/ylacancellera/galera-log-explainer/utils"
 )
@@ -31,6 +33,13 @@ type LogCtx struct {
 	IPToNodeName           map[string]string
 	minVerbosity           Verbosity
 	Conflicts              Conflicts
+
+	// ClockSkew is how much this node's clock was estimated to be ahead of the others. nil when it was not estimated
+	ClockSkew *time.Duration
+
+	// ClusterUUID and Seqno are the latest replication position known for the node, see SetPosition
+	ClusterUUID string
+	Seqno       string
 }
 
 func NewLogCtx() LogCtx {
@@ -81,6 +90,22 @@ func (ctx *LogCtx) SetState(s string) {
 	}
 }
 
+// SetPosition records the latest replication position logged by the node
+// uuid can be empty when only a seqno was logged
+// undefined positions (00000000-0000-0000-0000-000000000000:-1) are ignored, the last known one is more useful
+func (ctx *LogCtx) SetPosition(uuid, seqno string) {
+	if uuid != "" && uuid != undefinedUUID && uuid != ctx.ClusterUUID {
+		// seqnos from another cluster UUID can't be compared
+		ctx.ClusterUUID = uuid
+		ctx.Seqno = ""
+	}
+	if seqno != "" && !strings.HasPrefix(seqno, "-") {
+		ctx.Seqno = seqno
+	}
+}
+
+const undefinedUUID = "00000000-0000-0000-0000-000000000000"
+
 func (ctx *LogCtx) HasVisibleEvents(level Verbosity) bool {
 	return level >= ctx.minVerbosity
 }
@@ -234,34 +259,41 @@ func (base *LogCtx) Inherit(ctx LogCtx) {
 	base.MergeMapsWith([]LogCtx{ctx})
 }
 
+// logCtxJSON exposes the states, so that saved contexts are complete
+type logCtxJSON struct {
+	FilePath               string
+	FileType               string
+	OwnIPs                 []string
+	OwnHashes              []string
+	OwnNames               []string
+	StateErrorLog          string
+	StateRecoveryLog       string
+	StatePostProcessingLog string
+	StateBackupLog         string
+	Version                string
+	SST                    SST
+	MyIdx                  string
+	MemberCount            int
+	Desynced               bool
+	HashToIP               map[string]string
+	HashToNodeName         map[string]string
+	IPToHostname           map[string]string
+	IPToMethod             map[string]string
+	IPToNodeName           map[string]string
+	MinVerbosity           Verbosity
+	Conflicts              Conflicts
+	ClockSkew              *time.Duration `json:",omitempty"`
+	ClusterUUID            string         `json:",omitempty"`
+	Seqno                  string         `json:",omitempty"`
+}
+
 func (l LogCtx) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		FilePath               string
-		FileType               string
-		OwnIPs                 []string
-		OwnHashes              []string
-		OwnNames               []string
-		StateErrorLog          string
-		StateRecoveryLog       string
-		StatePostProcessingLog string
-		StateBackupLog         string
-		Version                string
-		SST                    SST
-		MyIdx                  string
-		MemberCount            int
-		Desynced               bool
-		HashToIP               map[string]string
-		HashToNodeName         map[string]string
-		IPToHostname           map[string]string
-		IPToMethod             map[string]string
-		IPToNodeName           map[string]string
-		MinVerbosity           Verbosity
-		Conflicts              Conflicts
-	}{
+	return json.Marshal(logCtxJSON{
 		FilePath:               l.FilePath,
 		FileType:               l.FileType,
 		OwnIPs:                 l.OwnIPs,
 		OwnHashes:              l.OwnHashes,
+		OwnNames:               l.OwnNames,
 		StateErrorLog:          l.stateErrorLog,
 		StateRecoveryLog:       l.stateRecoveryLog,
 		StatePostProcessingLog: l.statePostProcessingLog,
@@ -278,5 +310,52 @@ func (l LogCtx) MarshalJSON() ([]byte, error) {
 		IPToNodeName:           l.IPToNodeName,
 		MinVerbosity:           l.minVerbosity,
 		Conflicts:              l.Conflicts,
+		ClockSkew:              l.ClockSkew,
+		ClusterUUID:            l.ClusterUUID,
+		Seqno:                  l.Seqno,
 	})
 }
+
+func (l *LogCtx) UnmarshalJSON(b []byte) error {
+	var j logCtxJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*l = NewLogCtx()
+	l.FilePath = j.FilePath
+	l.FileType = j.FileType
+	l.OwnIPs = j.OwnIPs
+	l.OwnHashes = j.OwnHashes
+	l.OwnNames = j.OwnNames
+	l.stateErrorLog = j.StateErrorLog
+	l.stateRecoveryLog = j.StateRecoveryLog
+	l.statePostProcessingLog = j.StatePostProcessingLog
+	l.stateBackupLog = j.StateBackupLog
+	l.Version = j.Version
+	l.SST = j.SST
+	l.MyIdx = j.MyIdx
+	l.MemberCount = j.MemberCount
+	l.Desynced = j.Desynced
+	l.minVerbosity = j.MinVerbosity
+	l.Conflicts = j.Conflicts
+	l.ClockSkew = j.ClockSkew
+	l.ClusterUUID = j.ClusterUUID
+	l.Seqno = j.Seqno
+
+	// maps are expected to be usable, even when nothing was saved
+	for _, m := range []struct {
+		dst *map[string]string
+		src map[string]string
+	}{
+		{&l.HashToIP, j.HashToIP},
+		{&l.HashToNodeName, j.HashToNodeName},
+		{&l.IPToHostname, j.IPToHostname},
+		{&l.IPToMethod, j.IPToMethod},
+		{&l.IPToNodeName, j.IPToNodeName},
+	} {
+		if m.src != nil {
+			*m.dst = m.src
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"strconv"
+
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// Displayer is the message of an event, kept as data so that timelines can be saved and rendered later
+// Kind selects how it is rendered, Params is what the handler found in the log
+type Displayer struct {
+	Kind   string            `json:"kind"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// LogDisplayer is what handlers return to generate messages thanks to a context
+// The context given to Render should be as updated as possible
+type LogDisplayer = *Displayer
+
+// DisplayerRenderer crafts the message of a kind of Displayer
+type DisplayerRenderer func(ctx LogCtx, params map[string]string) string
+
+var displayerRenderers = map[string]DisplayerRenderer{}
+
+// RegisterDisplayer is to be called from init() functions
+// kinds are saved in snapshots, they should not be renamed
+func RegisterDisplayer(kind string, render DisplayerRenderer) {
+	if _, ok := displayerRenderers[kind]; ok {
+		panic("displayer kind registered twice: " + kind)
+	}
+	displayerRenderers[kind] = render
+}
+
+func NewDisplayer(kind string, params map[string]string) LogDisplayer {
+	return &Displayer{Kind: kind, Params: params}
+}
+
+// Render returns an empty message for nil displayers
+func (d *Displayer) Render(ctx LogCtx) string {
+	if d == nil {
+		return ""
+	}
+	render, ok := displayerRenderers[d.Kind]
+	if !ok {
+		// most probably a snapshot saved by a newer version
+		return "(unknown message: " + d.Kind + ")"
+	}
+	msg := render(ctx, d.Params)
+
+	// params can be painted by handlers, and snapshots by another run
+	if utils.SkipColor {
+		msg = utils.StripColors(msg)
+	}
+	return msg
+}
+
+func init() {
+	RegisterDisplayer("simple", func(_ LogCtx, params map[string]string) string {
+		return params["msg"]
+	})
+	RegisterDisplayer("node", func(ctx LogCtx, params map[string]string) string {
+		return params["before"] + DisplayNodeSimplestForm(ctx, params["ip"]) + params["after"]
+	})
+	RegisterDisplayer("segments", func(ctx LogCtx, params map[string]string) string {
+		msg := ""
+		for i := 0; ; i++ {
+			n := strconv.Itoa(i)
+			text, ok := params["text"+n]
+			if !ok {
+				return msg
+			}
+			if params["node"+n] != "" {
+				text = DisplayNodeSimplestForm(ctx, text)
+			}
+			msg += utils.PaintNamed(params["color"+n], text)
+		}
+	})
+}
+
+// Segment is a part of a message
+// colors and nodes are only applied when rendered: saved timelines do not depend on --no-color, and nodes use the latest context
+type Segment struct {
+	Text  string
+	Color string // see utils.ColorName
+	Node  bool   // Text is an ip, shown in its simplest form
+}
+
+func Plain(text string) Segment {
+	return Segment{Text: text}
+}
+
+func Painted(color utils.Color, text string) Segment {
+	return Segment{Text: text, Color: utils.ColorName(color)}
+}
+
+func Node(ip string) Segment {
+	return Segment{Text: ip, Node: true}
+}
+
+// SegmentsDisplayer renders segments one after the other
+func SegmentsDisplayer(segments ...Segment) LogDisplayer {
+	params := map[string]string{}
+	for i, segment := range segments {
+		n := strconv.Itoa(i)
+		params["text"+n] = segment.Text
+		if segment.Color != "" {
+			params["color"+n] = segment.Color
+		}
+		if segment.Node {
+			params["node"+n] = "true"
+		}
+	}
+	return NewDisplayer("segments", params)
+}
+
+// PaintedDisplayer is a message painted in a single color
+func PaintedDisplayer(color utils.Color, msg string) LogDisplayer {
+	return SegmentsDisplayer(Painted(color, msg))
+}
+
+// SimpleDisplayer ignores any context received
+func SimpleDisplayer(s string) LogDisplayer {
+	return NewDisplayer("simple", map[string]string{"msg": s})
+}
+
+// NodeDisplayer shows ip in its simplest form, known from the latest context, between before and after
+func NodeDisplayer(before, ip, after string) LogDisplayer {
+	return NewDisplayer("node", map[string]string{"before": before, "ip": ip, "after": after})
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+func TestSegmentsDisplayer(t *testing.T) {
+	defer func(skip bool) { utils.SkipColor = skip }(utils.SkipColor)
+
+	utils.SkipColor = false
+	d := SegmentsDisplayer(Painted(utils.YellowText, "IST to "), Node("10.0.0.2"), Plain("(seqno:20)"))
+
+	// saved timelines should not depend on the colors used when they were saved
+	for k, v := range d.Params {
+		if strings.Contains(v, "\x1b") {
+			t.Errorf("param %s is painted: %q", k, v)
+		}
+	}
+
+	// nodes are shown with what the latest context knows
+	ctx := NewLogCtx()
+	ctx.IPToNodeName = map[string]string{"10.0.0.2": "node2"}
+
+	expected := utils.Paint(utils.YellowText, "IST to ") + "node2(seqno:20)"
+	if out := d.Render(ctx); out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+
+	utils.SkipColor = true
+	if out := d.Render(ctx); out != "IST to node2(seqno:20)" {
+		t.Errorf("expected no colors, got %q", out)
+	}
+}
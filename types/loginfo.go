@@ -1,7 +1,9 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ylacancellera/galera-log-explainer/utils"
@@ -56,7 +58,7 @@ func (li *LogInfo) Msg(ctx LogCtx) string {
 	if li.RepetitionCount > 0 {
 		msg += utils.Paint(utils.BlueText, fmt.Sprintf("(repeated x%d)", li.RepetitionCount))
 	}
-	msg += li.displayer(ctx)
+	msg += li.displayer.Render(ctx)
 	for _, note := range li.extraNotes {
 		msg += utils.Paint(utils.BlueText, fmt.Sprintf("(%s)", note))
 	}
@@ -68,30 +70,122 @@ func (li *LogInfo) Msg(ctx LogCtx) string {
 func (current *LogInfo) IsDuplicatedEvent(base, previous LogInfo) bool {
 	return base.RegexUsed == previous.RegexUsed &&
 		base.displayer != nil && previous.displayer != nil && current.displayer != nil &&
-		base.displayer(base.Ctx) == previous.displayer(previous.Ctx) &&
+		base.displayer.Render(base.Ctx) == previous.displayer.Render(previous.Ctx) &&
 		previous.RegexUsed == current.RegexUsed &&
-		previous.displayer(previous.Ctx) == current.displayer(current.Ctx)
+		previous.displayer.Render(previous.Ctx) == current.displayer.Render(current.Ctx)
+}
+
+// logInfoJSON is how events are saved, displayers included
+type logInfoJSON struct {
+	Date            *Date
+	Displayer       LogDisplayer `json:",omitempty"`
+	Log             string       `json:",omitempty"`
+	RegexType       RegexType
+	RegexUsed       string
+	Ctx             LogCtx
+	Verbosity       Verbosity
+	RepetitionCount int
+	ExtraNotes      map[string]string `json:",omitempty"`
+}
+
+func (li LogInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(logInfoJSON{
+		Date:            li.Date,
+		Displayer:       li.displayer,
+		Log:             li.Log,
+		RegexType:       li.RegexType,
+		RegexUsed:       li.RegexUsed,
+		Ctx:             li.Ctx,
+		Verbosity:       li.Verbosity,
+		RepetitionCount: li.RepetitionCount,
+		ExtraNotes:      li.extraNotes,
+	})
+}
+
+func (li *LogInfo) UnmarshalJSON(b []byte) error {
+	var j logInfoJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*li = LogInfo{
+		Date:            j.Date,
+		displayer:       j.Displayer,
+		Log:             j.Log,
+		RegexType:       j.RegexType,
+		RegexUsed:       j.RegexUsed,
+		Ctx:             j.Ctx,
+		Verbosity:       j.Verbosity,
+		RepetitionCount: j.RepetitionCount,
+		extraNotes:      j.ExtraNotes,
+	}
+	if li.extraNotes == nil {
+		li.extraNotes = map[string]string{}
+	}
+	return nil
 }
 
 type Date struct {
 	Time        time.Time
 	DisplayTime string
 	Layout      string
+
+	displayLayout string
 }
 
 func NewDate(t time.Time, layout string) *Date {
 	return &Date{
-		Time:        t,
-		Layout:      layout,
-		DisplayTime: t.Format(layout),
+		Time:          t,
+		Layout:        layout,
+		DisplayTime:   t.Format(layout),
+		displayLayout: layout,
 	}
 }
 
-// LogDisplayer is the handler to generate messages thanks to a context
-// The context in parameters should be as updated as possible
-type LogDisplayer func(LogCtx) string
+// NewDateIn is used when dates are normalized to a single zone
+// the layout of the log is kept, but the offset is always shown as it may not be the one written in logs
+func NewDateIn(t time.Time, layout string, loc *time.Location) *Date {
+	t = t.In(loc)
+	displayLayout := strings.TrimSuffix(strings.TrimSuffix(layout, "-07:00"), "Z") + "Z07:00"
+	return &Date{
+		Time:          t,
+		Layout:        layout,
+		DisplayTime:   t.Format(displayLayout),
+		displayLayout: displayLayout,
+	}
+}
+
+// dateJSON keeps how the date was displayed, as zones can't be found back from offsets
+type dateJSON struct {
+	Time          time.Time
+	DisplayTime   string
+	Layout        string
+	DisplayLayout string `json:",omitempty"`
+}
+
+func (date Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dateJSON{Time: date.Time, DisplayTime: date.DisplayTime, Layout: date.Layout, DisplayLayout: date.displayLayout})
+}
+
+func (date *Date) UnmarshalJSON(b []byte) error {
+	var j dateJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*date = Date{Time: j.Time, DisplayTime: j.DisplayTime, Layout: j.Layout, displayLayout: j.DisplayLayout}
+	return nil
+}
 
-// SimpleDisplayer satisfies LogDisplayer and ignores any context received
-func SimpleDisplayer(s string) LogDisplayer {
-	return func(_ LogCtx) string { return s }
+// Add returns a new date shifted by d, displayed the same way
+func (date *Date) Add(d time.Duration) *Date {
+	displayLayout := date.displayLayout
+	if displayLayout == "" {
+		displayLayout = date.Layout
+	}
+	t := date.Time.Add(d)
+	return &Date{
+		Time:          t,
+		Layout:        date.Layout,
+		DisplayTime:   t.Format(displayLayout),
+		displayLayout: displayLayout,
+	}
 }
@@ -1,7 +1,9 @@
 package types
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestIsDuplicatedEvent(t *testing.T) {
@@ -51,3 +53,80 @@ func TestIsDuplicatedEvent(t *testing.T) {
 	}
 
 }
+
+func TestNewDateIn(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("no timezone database")
+	}
+	tests := []struct {
+		t        time.Time
+		layout   string
+		loc      *time.Location
+		expected string
+	}{
+		{
+			t:        time.Date(2023, time.May, 4, 10, 0, 0, 0, paris),
+			layout:   "2006-01-02 15:04:05",
+			loc:      time.UTC,
+			expected: "2023-05-04 08:00:00Z",
+		},
+		{
+			t:        time.Date(2023, time.May, 4, 10, 0, 0, 0, time.UTC),
+			layout:   "2006-01-02T15:04:05.000000Z",
+			loc:      paris,
+			expected: "2023-05-04T12:00:00.000000+02:00",
+		},
+		{
+			t:        time.Date(2023, time.May, 4, 10, 0, 0, 0, time.FixedZone("", -6*3600)),
+			layout:   "2006-01-02T15:04:05.000000-07:00",
+			loc:      time.UTC,
+			expected: "2023-05-04T16:00:00.000000Z",
+		},
+	}
+
+	for _, test := range tests {
+		d := NewDateIn(test.t, test.layout, test.loc)
+		if d.DisplayTime != test.expected || d.Layout != test.layout || !d.Time.Equal(test.t) {
+			t.Errorf("expected %s, got %s (layout %s)", test.expected, d.DisplayTime, d.Layout)
+		}
+	}
+}
+
+func TestLogInfoJSON(t *testing.T) {
+	ctx := NewLogCtx()
+	ctx.SetState("DONOR")
+	ctx.IPToNodeName["172.17.0.2"] = "node2"
+	date := NewDateIn(time.Date(2023, time.May, 4, 10, 0, 0, 0, time.UTC), "2006-01-02T15:04:05.000000Z", time.UTC)
+	li := NewLogInfo(date, NodeDisplayer("SST to ", "172.17.0.2", ""), "some log", &LogRegex{Type: SSTRegexType}, "RegexSSTStreamingTo", ctx, "")
+
+	b, err := json.Marshal(li)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded LogInfo
+	if err := json.Unmarshal(b, &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg := loaded.Msg(loaded.Ctx); msg != "SST to node2" {
+		t.Errorf("expected message to be rendered from the saved context, got %q", msg)
+	}
+	if loaded.Ctx.State() != "DONOR" {
+		t.Errorf("expected state to be saved, got %q", loaded.Ctx.State())
+	}
+	if !loaded.Date.Time.Equal(date.Time) || loaded.Date.DisplayTime != date.DisplayTime {
+		t.Errorf("expected date %v, got %v", date, loaded.Date)
+	}
+	if shifted := loaded.Date.Add(time.Hour); shifted.DisplayTime != date.Add(time.Hour).DisplayTime {
+		t.Errorf("expected date to be displayed the same way once loaded, got %s", shifted.DisplayTime)
+	}
+	if loaded.RegexUsed != li.RegexUsed || loaded.RegexType != li.RegexType || loaded.Log != li.Log {
+		t.Errorf("expected %+v, got %+v", li, loaded)
+	}
+
+	var nilDisplayer LogDisplayer
+	if nilDisplayer.Render(ctx) != "" {
+		t.Errorf("nil displayers should render nothing")
+	}
+}
@@ -0,0 +1,473 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// Pseudonyms replaces node identifiers the same way everywhere, so that anonymized logs and timelines can still be read and correlated
+// Pseudonyms keep the format of what they replace: anonymized logs can still be given to this tool
+// It is saved as-is in mapping files, to be able to reverse pseudonyms
+type Pseudonyms struct {
+	IPs       map[string]string `json:"ips"`
+	UUIDs     map[string]string `json:"uuids"`
+	Hostnames map[string]string `json:"hostnames"`
+	NodeNames map[string]string `json:"nodeNames"`
+
+	// errors from inconsistency votes can contain schemas, tables and data
+	Errors map[string]string `json:"errors"`
+
+	// Paths are file and directory names that are not identifiers, they can still be hostnames the logs did not tell
+	// they are only replaced in paths: "log" or "mysql" should not be replaced in messages
+	Paths map[string]string `json:"paths"`
+
+	replacer *pseudonymReplacer
+	reverser *pseudonymReplacer
+}
+
+func NewPseudonyms() *Pseudonyms {
+	p := &Pseudonyms{}
+	p.init()
+	return p
+}
+
+// init is needed after loading a mapping file, where some maps can be missing
+func (p *Pseudonyms) init() {
+	for _, m := range []*map[string]string{&p.IPs, &p.UUIDs, &p.Hostnames, &p.NodeNames, &p.Errors, &p.Paths} {
+		if *m == nil {
+			*m = map[string]string{}
+		}
+	}
+}
+
+// AddFromContexts collects every identifier known by the contexts
+// They are added in order, so that the same logs give the same pseudonyms
+func (p *Pseudonyms) AddFromContexts(ctxs map[string]LogCtx) {
+	p.init()
+	var ips, uuids, hostnames, names, errors []string
+	for _, ctx := range ctxs {
+		ips = append(ips, ctx.OwnIPs...)
+		uuids = append(uuids, ctx.OwnHashes...)
+		names = append(names, ctx.OwnNames...)
+		for hash, ip := range ctx.HashToIP {
+			uuids = append(uuids, hash)
+			ips = append(ips, ip)
+		}
+		for ip, hostname := range ctx.IPToHostname {
+			ips = append(ips, ip)
+			hostnames = append(hostnames, hostname)
+		}
+		for ip, name := range ctx.IPToNodeName {
+			ips = append(ips, ip)
+			names = append(names, name)
+		}
+		for hash, name := range ctx.HashToNodeName {
+			uuids = append(uuids, hash)
+			names = append(names, name)
+		}
+		for _, c := range ctx.Conflicts {
+			names = append(names, c.InitiatedBy...)
+			for name, vote := range c.VotePerNode {
+				names = append(names, name)
+				// what nodes vote when they did not fail, it is everywhere in logs
+				if vote.Error != "Success" {
+					errors = append(errors, vote.Error)
+				}
+			}
+		}
+	}
+
+	p.add(p.IPs, ips, func(_ string, n int) string {
+		return fmt.Sprintf("%s.%d", testNets[(n-1)/254%len(testNets)], (n-1)%254+1)
+	})
+	p.add(p.UUIDs, uuids, uuidPseudonym)
+	p.add(p.Hostnames, hostnames, func(_ string, n int) string { return fmt.Sprintf("anon-host-%d", n) })
+	p.add(p.NodeNames, names, func(_ string, n int) string { return fmt.Sprintf("anon-node-%d", n) })
+	p.add(p.Errors, errors, func(_ string, n int) string { return fmt.Sprintf("anon-error-%d", n) })
+	p.replacer, p.reverser = nil, nil
+}
+
+// uuidPseudonym keeps the form of the hash: full UUID, short UUID (see utils.UUIDToShortUUID), or only its first part
+func uuidPseudonym(uuid string, n int) string {
+	switch strings.Count(uuid, "-") {
+	case 0:
+		return fmt.Sprintf("%08x", n)
+	case 4:
+		return fmt.Sprintf("%08x-0000-0000-%04x-000000000000", n, n)
+	default:
+		return fmt.Sprintf("%08x-%04x", n, n)
+	}
+}
+
+// testNets are reserved for documentation, they can't be mistaken for actual IPs (RFC5737)
+var testNets = []string{"192.0.2", "198.51.100", "203.0.113"}
+
+func (p *Pseudonyms) add(m map[string]string, values []string, pseudonym func(string, int) string) {
+	sort.Strings(values)
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		// hostnames and node names are often the same
+		if _, ok := p.lookup(v); ok {
+			continue
+		}
+		m[v] = pseudonym(v, len(m)+1)
+	}
+}
+
+func (p *Pseudonyms) lookup(v string) (string, bool) {
+	for _, m := range []map[string]string{p.IPs, p.UUIDs, p.Hostnames, p.NodeNames, p.Errors} {
+		if pseudonym, ok := m[v]; ok {
+			return pseudonym, true
+		}
+	}
+	return "", false
+}
+
+// Replace anonymizes any text
+func (p *Pseudonyms) Replace(s string) string {
+	if p.replacer == nil {
+		p.replacer = newPseudonymReplacer(p.IPs, p.UUIDs, p.Hostnames, p.NodeNames, p.Errors)
+		// full UUIDs are then reversible
+		p.replacer.derived = p.UUIDs
+	}
+	return p.replacer.replace(s)
+}
+
+// ReplacePath anonymizes every component of a path, separators and extensions are kept
+// archive members are named archive:member, both are replaced
+func (p *Pseudonyms) ReplacePath(path string) string {
+	return pathComponentRegex.ReplaceAllStringFunc(path, p.ReplaceFileName)
+}
+
+var pathComponentRegex = regexp.MustCompile("[^/:]+")
+
+// ReplaceFileName anonymizes a single path component, keeping its extension
+// identifiers in names are joined with underscores (node1_error.log), they are replaced by their own pseudonyms
+// anything else is replaced too: names can be hostnames the logs did not tell
+func (p *Pseudonyms) ReplaceFileName(name string) string {
+	p.init()
+	if name == "." || name == ".." || name == "-" || name == "stdin" {
+		return name
+	}
+	stem, ext := splitExtension(name)
+	if pseudonym, ok := p.identifier(stem); ok {
+		return pseudonym + ext
+	}
+
+	parts := strings.Split(stem, "_")
+	found := false
+	for i, part := range parts {
+		if pseudonym, ok := p.identifier(part); ok {
+			parts[i] = pseudonym
+			found = true
+		}
+	}
+	if !found {
+		return p.pathPseudonym(stem) + ext
+	}
+	for i, part := range parts {
+		if part != "" && !p.isPseudonym(part) {
+			parts[i] = p.pathPseudonym(part)
+		}
+	}
+	return strings.Join(parts, "_") + ext
+}
+
+// identifier only gives pseudonyms of whole identifiers
+func (p *Pseudonyms) identifier(s string) (string, bool) {
+	if pseudonym, ok := p.lookup(s); ok {
+		return pseudonym, true
+	}
+	if fullUUIDRegex.MatchString(s) {
+		if replaced := p.Replace(s); replaced != s {
+			return replaced, true
+		}
+	}
+	return "", false
+}
+
+func (p *Pseudonyms) isPseudonym(s string) bool {
+	for _, m := range []map[string]string{p.IPs, p.UUIDs, p.Hostnames, p.NodeNames, p.Errors, p.Paths} {
+		for _, pseudonym := range m {
+			if pseudonym == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Pseudonyms) pathPseudonym(s string) string {
+	if pseudonym, ok := p.Paths[s]; ok {
+		return pseudonym
+	}
+	pseudonym := fmt.Sprintf("anon-path-%d", len(p.Paths)+1)
+	p.Paths[s] = pseudonym
+	p.reverser = nil
+	return pseudonym
+}
+
+// fileExtensions are kept as-is in paths, they tell how to read files
+var fileExtensions = []string{"log", "err", "txt", "out", "json", "tar", "tgz", "gz", "bz2", "zst"}
+
+// splitExtension returns the name without its extensions, rotated logs included (mysqld.log.1)
+// only known extensions are removed: ips and hostnames contain dots too
+func splitExtension(name string) (string, string) {
+	stem := name
+	for {
+		i := strings.LastIndex(stem, ".")
+		if i <= 0 {
+			break
+		}
+		ext := stem[i+1:]
+		if isDigits(ext) {
+			j := strings.LastIndex(stem[:i], ".")
+			if j <= 0 || !utils.SliceContains(fileExtensions, stem[j+1:i]) {
+				break
+			}
+			stem = stem[:j]
+			continue
+		}
+		if !utils.SliceContains(fileExtensions, ext) {
+			break
+		}
+		stem = stem[:i]
+	}
+	return stem, name[len(stem):]
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Reverse gives back the original identifiers of anonymized text
+func (p *Pseudonyms) Reverse(s string) string {
+	if p.reverser == nil {
+		reversed := []map[string]string{}
+		for _, m := range []map[string]string{p.IPs, p.UUIDs, p.Hostnames, p.NodeNames, p.Errors, p.Paths} {
+			r := map[string]string{}
+			for k, v := range m {
+				r[v] = k
+			}
+			reversed = append(reversed, r)
+		}
+		p.reverser = newPseudonymReplacer(reversed...)
+	}
+	return p.reverser.replace(s)
+}
+
+// AnonymizeTimeline returns a copy of the timeline where every identifier is replaced, in contexts, messages and logs
+func (p *Pseudonyms) AnonymizeTimeline(timeline Timeline) Timeline {
+	// paths are given pseudonyms in order, so that the same logs give the same pseudonyms
+	seen := map[string]bool{}
+	paths := []string{}
+	for _, lt := range timeline {
+		for _, li := range lt {
+			if !seen[li.Ctx.FilePath] {
+				seen[li.Ctx.FilePath] = true
+				paths = append(paths, li.Ctx.FilePath)
+			}
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		p.ReplacePath(path)
+	}
+
+	conflicts := map[*Conflict]*Conflict{}
+	t := make(Timeline, len(timeline))
+	for node, lt := range timeline {
+		newlt := make(LocalTimeline, len(lt))
+		for i, li := range lt {
+			li.Log = p.Replace(li.Log)
+			li.Ctx = p.anonymizeCtx(li.Ctx, conflicts)
+			if li.displayer != nil {
+				params := map[string]string{}
+				for k, v := range li.displayer.Params {
+					params[k] = p.Replace(v)
+				}
+				li.displayer = NewDisplayer(li.displayer.Kind, params)
+			}
+			notes := map[string]string{}
+			for k, v := range li.extraNotes {
+				notes[k] = p.Replace(v)
+			}
+			li.extraNotes = notes
+			newlt[i] = li
+		}
+		// nodes are named after their files when they could not be identified
+		t[p.ReplacePath(node)] = newlt
+	}
+	return t
+}
+
+// conflicts are shared between contexts, they are copied once to keep it that way
+func (p *Pseudonyms) anonymizeCtx(ctx LogCtx, conflicts map[*Conflict]*Conflict) LogCtx {
+	ctx.FilePath = p.ReplacePath(ctx.FilePath)
+	ctx.OwnIPs = p.replaceSlice(ctx.OwnIPs)
+	ctx.OwnHashes = p.replaceSlice(ctx.OwnHashes)
+	ctx.OwnNames = p.replaceSlice(ctx.OwnNames)
+	ctx.SST.ResyncingNode = p.Replace(ctx.SST.ResyncingNode)
+	ctx.SST.ResyncedFromNode = p.Replace(ctx.SST.ResyncedFromNode)
+	ctx.HashToIP = p.replaceMap(ctx.HashToIP)
+	ctx.HashToNodeName = p.replaceMap(ctx.HashToNodeName)
+	ctx.IPToHostname = p.replaceMap(ctx.IPToHostname)
+	ctx.IPToMethod = p.replaceMap(ctx.IPToMethod)
+	ctx.IPToNodeName = p.replaceMap(ctx.IPToNodeName)
+
+	newConflicts := make(Conflicts, len(ctx.Conflicts))
+	for i, c := range ctx.Conflicts {
+		if _, ok := conflicts[c]; !ok {
+			votes := map[string]ConflictVote{}
+			for node, vote := range c.VotePerNode {
+				vote.Error = p.Replace(vote.Error)
+				votes[p.Replace(node)] = vote
+			}
+			conflicts[c] = &Conflict{Seqno: c.Seqno, InitiatedBy: p.replaceSlice(c.InitiatedBy), Winner: c.Winner, VotePerNode: votes}
+		}
+		newConflicts[i] = conflicts[c]
+	}
+	ctx.Conflicts = newConflicts
+	return ctx
+}
+
+func (p *Pseudonyms) replaceSlice(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	replaced := make([]string, len(s))
+	for i, v := range s {
+		replaced[i] = p.Replace(v)
+	}
+	return replaced
+}
+
+func (p *Pseudonyms) replaceMap(m map[string]string) map[string]string {
+	replaced := make(map[string]string, len(m))
+	for k, v := range m {
+		replaced[p.Replace(k)] = p.Replace(v)
+	}
+	return replaced
+}
+
+// pseudonymReplacer only replaces whole identifiers: 10.0.0.1 is not replaced in 10.0.0.12
+type pseudonymReplacer struct {
+	mapping map[string]string
+	re      *regexp.Regexp
+
+	// derived receives full UUIDs that were replaced thanks to their short form
+	derived map[string]string
+}
+
+var fullUUIDRegex = regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$")
+
+func newPseudonymReplacer(maps ...map[string]string) *pseudonymReplacer {
+	r := &pseudonymReplacer{mapping: map[string]string{}}
+	keys := []string{}
+	for _, m := range maps {
+		for k, v := range m {
+			r.mapping[k] = v
+			keys = append(keys, k)
+		}
+	}
+
+	// the longest first, alternations are tried in order
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	// full UUIDs are mostly known by their short form, see utils.UUIDToShortUUID
+	// they come first, their first part would be matched alone otherwise
+	alternatives := []string{"[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}"}
+	for _, k := range keys {
+		alternatives = append(alternatives, regexp.QuoteMeta(k))
+	}
+	r.re = regexp.MustCompile(strings.Join(alternatives, "|"))
+	return r
+}
+
+func (r *pseudonymReplacer) replace(s string) string {
+	if s == "" {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range r.re.FindAllStringIndex(s, -1) {
+		start, end := loc[0], loc[1]
+		if !isIdentifierBoundary(s, start, end) {
+			continue
+		}
+		replacement, ok := r.lookup(s[start:end])
+		if !ok {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(replacement)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func (r *pseudonymReplacer) lookup(match string) (string, bool) {
+	if replacement, ok := r.mapping[match]; ok {
+		return replacement, true
+	}
+	if !fullUUIDRegex.MatchString(match) {
+		return "", false
+	}
+	short, ok := r.mapping[utils.UUIDToShortUUID(match)]
+	if !ok {
+		return "", false
+	}
+	// the full form is built so that its short form is the short pseudonym
+	parts := strings.SplitN(short, "-", 2)
+	if len(parts) != 2 {
+		return "", false
+	}
+	full := fmt.Sprintf("%s-0000-0000-%s-000000000000", parts[0], parts[1])
+	r.mapping[match] = full
+	if r.derived != nil {
+		r.derived[match] = full
+	}
+	return full, true
+}
+
+func endsWithColor(s string) bool {
+	codes := utils.ColorCodes(s)
+	return len(codes) > 0 && codes[len(codes)-1][1] == len(s)
+}
+
+// isIdentifierBoundary checks the match is not part of a longer word, number or ip
+// colors are not part of words, messages can be painted
+func isIdentifierBoundary(s string, start, end int) bool {
+	if start > 0 && isWordChar(s[start-1]) && !endsWithColor(s[:start]) {
+		return false
+	}
+	if end < len(s) {
+		if isWordChar(s[end]) {
+			return false
+		}
+		if s[end] == '.' && end+1 < len(s) && isWordChar(s[end+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
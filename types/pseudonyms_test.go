@@ -0,0 +1,181 @@
+package types
+
+import (
+	"testing"
+)
+
+func testPseudonyms() *Pseudonyms {
+	p := NewPseudonyms()
+	p.AddFromContexts(map[string]LogCtx{
+		"node1": LogCtx{
+			OwnIPs:         []string{"10.0.0.1"},
+			OwnNames:       []string{"node1"},
+			HashToIP:       map[string]string{"838ebd6d-a9eb": "10.0.0.1", "9509c194-a4ca": "10.0.0.12"},
+			HashToNodeName: map[string]string{"838ebd6d-a9eb": "node1", "9509c194-a4ca": "node2"},
+			IPToHostname:   map[string]string{"10.0.0.12": "db2.example.com"},
+			Conflicts: Conflicts{&Conflict{Seqno: "20", InitiatedBy: []string{"node1"}, VotePerNode: map[string]ConflictVote{
+				"node1": ConflictVote{Error: "Duplicate entry '1' for key 'shop.orders.PRIMARY'"},
+				"node2": ConflictVote{Error: "Success"},
+			}}},
+		},
+	})
+	return p
+}
+
+func TestPseudonymsReplace(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "ips are not replaced inside other ips",
+			input:    "connection to 10.0.0.1:4567 and tcp://10.0.0.12:4567",
+			expected: "connection to 192.0.2.1:4567 and tcp://192.0.2.2:4567",
+		},
+		{
+			name:     "names are not replaced inside words",
+			input:    "Member 0.0 (node1) synced, node10 and mynode1 are not node1.",
+			expected: "Member 0.0 (anon-node-1) synced, node10 and mynode1 are not anon-node-1.",
+		},
+		{
+			name:     "hostnames are replaced before the names they contain",
+			input:    "db2.example.com, db2",
+			expected: "anon-host-1, db2",
+		},
+		{
+			name:     "full uuids are replaced from their short form",
+			input:    "838ebd6d-a9eb, 838ebd6d-32f7-11ed-a9eb-af5e3d01519e, 8c9b5610-e020-11ed-a5ea-e253cc5f629d",
+			expected: "00000001-0001, 00000001-0000-0000-0001-000000000000, 8c9b5610-e020-11ed-a5ea-e253cc5f629d",
+		},
+		{
+			name:     "errors can contain schemas",
+			input:    "initiates vote on 8c9b5610-e020-11ed-a5ea-e253cc5f629d:20,bdb2b9234ae75cb3:  Duplicate entry '1' for key 'shop.orders.PRIMARY', Error_code: 1062; Success",
+			expected: "initiates vote on 8c9b5610-e020-11ed-a5ea-e253cc5f629d:20,bdb2b9234ae75cb3:  anon-error-1, Error_code: 1062; Success",
+		},
+		{
+			name:     "painted messages",
+			input:    "\x1b[0033mSST to \x1b[0000mnode1",
+			expected: "\x1b[0033mSST to \x1b[0000manon-node-1",
+		},
+	}
+
+	for _, test := range tests {
+		p := testPseudonyms()
+		out := p.Replace(test.input)
+		if out != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, out)
+		}
+		if reversed := p.Reverse(out); reversed != test.input {
+			t.Errorf("%s: could not reverse, expected %q, got %q", test.name, test.input, reversed)
+		}
+	}
+}
+
+func TestPseudonymsReplaceFileName(t *testing.T) {
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "node1.log", expected: "anon-node-1.log"},
+		{input: "node1.log.1", expected: "anon-node-1.log.1"},
+		{input: "db2.example.com", expected: "anon-host-1"},
+		{input: "db2.example.com.err", expected: "anon-host-1.err"},
+		{input: "10.0.0.12.log", expected: "192.0.2.2.log"},
+		{input: "10.0.0.12.log.2", expected: "192.0.2.2.log.2"},
+		{input: "node2_mysqld.log", expected: "anon-node-2_anon-path-1.log"},
+		{input: "node10.log", expected: "anon-path-2.log"},
+		{input: "mysqld.log.gz", expected: "anon-path-1.log.gz"},
+		{input: "10.0.0.3", expected: "anon-path-3"},
+	}
+
+	p := testPseudonyms()
+	for _, test := range tests {
+		if out := p.ReplaceFileName(test.input); out != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.input, test.expected, out)
+		}
+	}
+}
+
+func TestPseudonymsReplacePath(t *testing.T) {
+	p := testPseudonyms()
+
+	out := p.ReplacePath("/var/log/db2.example.com/10.0.0.12.log")
+	if out != "/anon-path-1/anon-path-2/anon-host-1/192.0.2.2.log" {
+		t.Errorf("unexpected path: %q", out)
+	}
+	out = p.ReplacePath("bundle.tar.gz:log/node1.log")
+	if out != "anon-path-3.tar.gz:anon-path-2/anon-node-1.log" {
+		t.Errorf("unexpected archive member: %q", out)
+	}
+
+	// path pseudonyms are only reversed, "log" would be replaced in every message otherwise
+	if replaced := p.Replace("log"); replaced != "log" {
+		t.Errorf("path components should not be replaced in text: %q", replaced)
+	}
+	if reversed := p.Reverse("in anon-path-2/anon-node-1"); reversed != "in log/node1" {
+		t.Errorf("could not reverse path: %q", reversed)
+	}
+}
+
+func TestPseudonymsStable(t *testing.T) {
+	p := testPseudonyms()
+	before := p.Replace("node1 node2 10.0.0.12")
+
+	// identifiers met later should not change the pseudonyms already given
+	p.AddFromContexts(map[string]LogCtx{"node0": LogCtx{OwnIPs: []string{"10.0.0.0"}, OwnNames: []string{"node0"}}})
+	if after := p.Replace("node1 node2 10.0.0.12"); after != before {
+		t.Errorf("pseudonyms changed: %q, then %q", before, after)
+	}
+	if out := p.Replace("node0 10.0.0.0"); out != "anon-node-3 192.0.2.3" {
+		t.Errorf("unexpected pseudonyms for new identifiers: %q", out)
+	}
+}
+
+func TestPseudonymsAnonymizeTimeline(t *testing.T) {
+	p := testPseudonyms()
+	conflict := &Conflict{Seqno: "20", InitiatedBy: []string{"node1"}, VotePerNode: map[string]ConflictVote{"node1": ConflictVote{Error: "Duplicate entry '1' for key 'shop.orders.PRIMARY'"}}}
+	ctx := LogCtx{
+		FilePath:     "/var/log/node1/mysqld.log",
+		OwnNames:     []string{"node1"},
+		IPToNodeName: map[string]string{"10.0.0.12": "node2"},
+		Conflicts:    Conflicts{conflict},
+	}
+	timeline := Timeline{"node1": LocalTimeline{
+		LogInfo{Log: "Member 1(node1) initiates vote", displayer: NodeDisplayer("SST to ", "10.0.0.12", ""), Ctx: ctx},
+		LogInfo{displayer: SimpleDisplayer("node2 joined"), Ctx: ctx},
+	}}
+
+	anonymized := p.AnonymizeTimeline(timeline)
+	lt, ok := anonymized["anon-node-1"]
+	if !ok || len(lt) != 2 {
+		t.Fatalf("unexpected anonymized timeline: %v", anonymized)
+	}
+	if lt[0].Log != "Member 1(anon-node-1) initiates vote" {
+		t.Errorf("log not anonymized: %q", lt[0].Log)
+	}
+	if lt[0].Ctx.FilePath != "/anon-path-1/anon-path-2/anon-node-1/anon-path-3.log" || lt[0].Ctx.OwnNames[0] != "anon-node-1" || lt[0].Ctx.IPToNodeName["192.0.2.2"] != "anon-node-2" {
+		t.Errorf("context not anonymized: %+v", lt[0].Ctx)
+	}
+	if out := lt[0].Msg(lt[0].Ctx); out != "SST to anon-node-2" {
+		t.Errorf("message not anonymized: %q", out)
+	}
+	if out := lt[1].Msg(lt[1].Ctx); out != "anon-node-2 joined" {
+		t.Errorf("message not anonymized: %q", out)
+	}
+
+	c := lt[0].Ctx.Conflicts[0]
+	if c != lt[1].Ctx.Conflicts[0] {
+		t.Errorf("conflicts should still be shared between contexts")
+	}
+	if c.InitiatedBy[0] != "anon-node-1" || c.VotePerNode["anon-node-1"].Error != "anon-error-1" {
+		t.Errorf("conflict not anonymized: %+v", c)
+	}
+
+	// the original timeline is left as-is
+	if timeline["node1"][0].Ctx.OwnNames[0] != "node1" || conflict.InitiatedBy[0] != "node1" {
+		t.Errorf("original timeline was modified")
+	}
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/json"
 	"regexp"
+	"sort"
 )
 
 // LogRegex is the work struct to work on lines that were sent by "grep"
@@ -12,24 +13,38 @@ type LogRegex struct {
 	InternalRegex *regexp.Regexp // for internal usage in handler func
 	Type          RegexType
 
-	// Taking into arguments the current context and log line, returning an updated context and a closure to get the msg to display
-	// Why a closure: to later inject an updated context instead of the current partial context
+	// Taking into arguments the current context and log line, returning an updated context and a displayer to get the msg to display
+	// Why a displayer: to later inject an updated context instead of the current partial context
 	// This ensure every hash/ip/nodenames are already known when crafting the message
 	Handler   func(map[string]string, LogCtx, string) (LogCtx, LogDisplayer)
 	Verbosity Verbosity // To be able to hide details from summaries
+
+	// Flavor is set for wordings only found in the logs of a single software (pxc, mariadb)
+	// it is skipped on files of the other flavors
+	Flavor string
 }
 
 func (l *LogRegex) Handle(ctx LogCtx, line string) (LogCtx, LogDisplayer) {
 	if ctx.minVerbosity > l.Verbosity {
 		ctx.minVerbosity = l.Verbosity
 	}
+	submatches := l.Submatches(line)
+	if submatches == nil {
+		return ctx, nil
+	}
+	return l.Handler(submatches, ctx, line)
+}
+
+// Submatches returns the named groups of InternalRegex found in line
+// nil means InternalRegex did not match
+func (l *LogRegex) Submatches(line string) map[string]string {
 	mergedResults := map[string]string{}
 	if l.InternalRegex == nil {
-		return l.Handler(mergedResults, ctx, line)
+		return mergedResults
 	}
 	slice := l.InternalRegex.FindStringSubmatch(line)
 	if len(slice) == 0 {
-		return ctx, nil
+		return nil
 	}
 	for _, subexpname := range l.InternalRegex.SubexpNames() {
 		if subexpname == "" { // 1st element is always empty for the complete regex
@@ -37,7 +52,7 @@ func (l *LogRegex) Handle(ctx LogCtx, line string) (LogCtx, LogDisplayer) {
 		}
 		mergedResults[subexpname] = slice[l.InternalRegex.SubexpIndex(subexpname)]
 	}
-	return l.Handler(mergedResults, ctx, line)
+	return mergedResults
 }
 
 func (l *LogRegex) MarshalJSON() ([]byte, error) {
@@ -46,9 +61,11 @@ func (l *LogRegex) MarshalJSON() ([]byte, error) {
 		InternalRegex string    `json:"internalRegex"`
 		Type          RegexType `json:"type"`
 		Verbosity     Verbosity `json:"verbosity"`
+		Flavor        string    `json:"flavor,omitempty"`
 	}{
 		Type:      l.Type,
 		Verbosity: l.Verbosity,
+		Flavor:    l.Flavor,
 	}
 	if l.Regex != nil {
 		out.Regex = l.Regex.String()
@@ -81,11 +98,13 @@ func (r RegexMap) Merge(r2 RegexMap) RegexMap {
 	return r
 }
 
+// Compile returns the regexes sorted, so that the same map always gives the same grep argument
 func (r RegexMap) Compile() []string {
 
 	arr := []string{}
 	for _, regex := range r {
 		arr = append(arr, regex.Regex.String())
 	}
+	sort.Strings(arr)
 	return arr
 }
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"fmt"
+	"regexp"
+	"regexp/syntax"
 	"strings"
 )
 
@@ -30,10 +32,34 @@ const (
 	BrightWhiteText         = "\x1b[1;37m"
 )
 
+// colors are saved by their names, codes are only for terminals
 var colorsToTextColor = map[string]Color{
-	"yellow": YellowText,
-	"green":  GreenText,
-	"red":    RedText,
+	"yellow":    YellowText,
+	"green":     GreenText,
+	"red":       RedText,
+	"blue":      BlueText,
+	"magenta":   MagentaText,
+	"cyan":      CyanText,
+	"brightred": BrightRedText,
+}
+
+// ColorName returns the name of a color, empty when it has none
+func ColorName(c Color) string {
+	for name, color := range colorsToTextColor {
+		if color == c {
+			return name
+		}
+	}
+	return ""
+}
+
+// PaintNamed is Paint, for colors known by their names. Unknown names are not painted
+func PaintNamed(name, value string) string {
+	c, ok := colorsToTextColor[name]
+	if !ok {
+		return value
+	}
+	return Paint(c, value)
 }
 
 var SkipColor bool
@@ -50,6 +76,18 @@ func Paint(color Color, value string) string {
 	return fmt.Sprintf("%v%v%v", color, value, ResetText)
 }
 
+var colorCodes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// StripColors removes what Paint added, for text painted before SkipColor was known
+func StripColors(value string) string {
+	return colorCodes.ReplaceAllString(value, "")
+}
+
+// ColorCodes returns where each color code is, as regexp's FindAllStringIndex would
+func ColorCodes(value string) [][]int {
+	return colorCodes.FindAllStringIndex(value, -1)
+}
+
 func PaintForState(text, state string) string {
 
 	c := ColorForState(state)
@@ -121,3 +159,39 @@ func ShortNodeName(s string) string {
 	before, _, _ := strings.Cut(s, ".")
 	return before
 }
+
+// RequiredLiteral returns the longest literal string that any match of the regex has to contain
+// It returns an empty string when it cannot be guessed, such as with alternations or case-insensitive patterns
+// It is used to quickly discard lines before trying the actual regex
+func RequiredLiteral(re *regexp.Regexp) string {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	return longestLiteral(parsed.Simplify())
+}
+
+func longestLiteral(re *syntax.Regexp) string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return ""
+		}
+		return string(re.Rune)
+	case syntax.OpCapture, syntax.OpPlus:
+		return longestLiteral(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return longestLiteral(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		longest := ""
+		for _, sub := range re.Sub {
+			if l := longestLiteral(sub); len(l) > len(longest) {
+				longest = l
+			}
+		}
+		return longest
+	}
+	return ""
+}
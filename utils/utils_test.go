@@ -1,6 +1,9 @@
 package utils
 
-import "testing"
+import (
+	"regexp"
+	"testing"
+)
 
 func TestStringsReplaceReverse(t *testing.T) {
 
@@ -40,3 +43,67 @@ func TestStringsReplaceReverse(t *testing.T) {
 		}
 	}
 }
+
+func TestRequiredLiteral(t *testing.T) {
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "Shifting",
+			expected: "Shifting",
+		},
+		{
+			input:    "requested state transfer.*Selected",
+			expected: "requested state transfer",
+		},
+		{
+			input:    ".ode consistency compromi.ed",
+			expected: "ode consistency compromi",
+		},
+		{
+			input:    "members.[0-9]+.:",
+			expected: "members",
+		},
+		{
+			input:    "Normal|Received shutdown",
+			expected: "",
+		},
+		{
+			input:    "(?i)shifting",
+			expected: "",
+		},
+		{
+			input:    "^{\"log\":\".*GCache::RingBuffer initial scan",
+			expected: "GCache::RingBuffer initial scan",
+		},
+	}
+	for _, test := range tests {
+		if s := RequiredLiteral(regexp.MustCompile(test.input)); s != test.expected {
+			t.Log("Expected", test.expected, "got", s)
+			t.Fail()
+		}
+	}
+}
+
+func TestColorCodes(t *testing.T) {
+
+	painted := "from " + string(GreenText) + "SYNCED" + string(ResetText) + " to " + string(BrightRedText) + "CLOSED" + string(ResetText)
+
+	if s := StripColors(painted); s != "from SYNCED to CLOSED" {
+		t.Log("Expected colors to be removed, got", s)
+		t.Fail()
+	}
+
+	codes := ColorCodes(painted)
+	if len(codes) != 4 {
+		t.Fatal("Expected 4 color codes, got", codes)
+	}
+	for i, code := range []Color{GreenText, ResetText, BrightRedText, ResetText} {
+		if s := painted[codes[i][0]:codes[i][1]]; s != string(code) {
+			t.Logf("Expected code %d to be %q, got %q", i, code, s)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/analysis"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+type views struct {
+	Paths []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Json  bool     `help:"Print the views as json"`
+}
+
+func (v *views) Help() string {
+	return `List the sequence of cluster views, with their members and the nodes that saw them
+
+Usage:
+	galera-log-explainer views *.log
+	galera-log-explainer views --json *.log
+
+Views seen by every node at the same time are merged. Two views listed at the same time with different members are partitions of the cluster
+Members are unknown when the logs did not list them, and neither joins and leaves nor established connections could explain the member count`
+}
+
+func (v *views) Run() error {
+
+	timeline, err := timelineFromPaths(v.Paths, regex.AllRegexes())
+	if err != nil {
+		return errors.Wrap(err, "Could not list views")
+	}
+
+	clusterViews := analysis.Views(timeline)
+
+	if v.Json {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "\t")
+		return e.Encode(clusterViews)
+	}
+
+	if len(clusterViews) == 0 {
+		fmt.Println("no view found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 8, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "date\tn\tmembers\tseen by\tstatus\t")
+	for _, view := range clusterViews {
+		status := utils.Paint(utils.GreenText, "PRIMARY")
+		if !view.Primary {
+			status = utils.Paint(utils.RedText, "NON-PRIMARY")
+		}
+		members := strings.Join(view.Members, ", ")
+		if members == "" {
+			members = "?"
+		}
+		fmt.Fprintln(w, strings.Join([]string{view.Date.Format(reportDateLayout), strconv.Itoa(view.MemberCount), members, strings.Join(view.SeenBy, ", "), status}, "\t")+"\t")
+	}
+	return w.Flush()
+}